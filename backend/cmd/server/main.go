@@ -1,21 +1,65 @@
 package main
 
 import (
+	"crypto/rand"
+	"flag"
+	"game-server/internal/auth"
+	"game-server/internal/game"
+	"game-server/internal/storage"
 	"game-server/internal/websocket"
 	"log"
 	"net/http"
+	"os"
 )
 
 func main() {
+	spellsPath := flag.String("spells", "data/spells.json", "path to the spell catalogue file")
+	mapsDir := flag.String("maps", "data/maps", "directory of the map files")
+	storePath := flag.String("store", "storage/games.json", "path to the file where finished games are recorded")
+	authSecret := flag.String("auth-secret", os.Getenv("AUTH_SECRET"), "secret signing the account tokens, random when empty")
+	flag.Parse()
+
+	// Load the spell catalogue
+	spells, err := game.LoadSpellCatalogue(*spellsPath)
+	if err != nil {
+		log.Fatal("Loading spell catalogue: ", err)
+	}
+	log.Printf("Loaded %d spells from %s", len(spells.List()), *spellsPath)
+
+	// Load the maps rooms can be played on
+	maps, err := game.LoadMapCatalogue(*mapsDir)
+	if err != nil {
+		log.Fatal("Loading maps: ", err)
+	}
+	log.Printf("Loaded %d maps from %s", len(maps.List()), *mapsDir)
+
+	// Load the recorded games and player stats
+	store, err := storage.OpenFileStore(*storePath)
+	if err != nil {
+		log.Fatal("Opening store: ", err)
+	}
+	defer store.Close()
+	games, _ := store.ListGames()
+	log.Printf("Loaded %d recorded games from %s", len(games), *storePath)
+
+	// Sign the account tokens with the configured secret. Without one, tokens
+	// do not survive a restart.
+	secret := []byte(*authSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatal("Generating auth secret: ", err)
+		}
+		log.Printf("[Warning] No auth secret set, account tokens will be invalid after a restart")
+	}
+	accounts := auth.NewAccounts(store, auth.NewTokens(secret))
+
 	// Create a new hub instance
-	hub := websocket.NewHub()
+	hub := websocket.NewHub(spells, maps, store, accounts)
 
 	// Start the hub
 	go hub.Run()
 
-	// Set up the WebSocket endpoint
-	http.HandleFunc("/ws", hub.HandleWebSocket)
-
 	// Configure CORS middleware
 	corsMiddleware := func(handler http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -35,10 +79,19 @@ func main() {
 	// Create a new mux and apply CORS middleware
 	mux := http.NewServeMux()
 	mux.HandleFunc("/ws", hub.HandleWebSocket)
+	mux.HandleFunc("GET /spells", hub.HandleSpellCatalogue)
+	mux.HandleFunc("GET /games", hub.HandleListGames)
+	mux.HandleFunc("GET /games/{id}", hub.HandleGetGame)
+	mux.HandleFunc("GET /games/{id}/replay", hub.HandleGetReplay)
+	mux.HandleFunc("GET /stats", hub.HandleListPlayerStats)
+	mux.HandleFunc("GET /stats/{userId}", hub.HandleGetPlayerStats)
+	mux.HandleFunc("GET /leaderboard", hub.HandleLeaderboard)
+	mux.HandleFunc("POST /auth/register", hub.HandleRegister)
+	mux.HandleFunc("POST /auth/login", hub.HandleLogin)
 
 	// Start the server
 	log.Printf("Starting server on :8080")
-	err := http.ListenAndServe(":8080", corsMiddleware(mux))
+	err = http.ListenAndServe(":8080", corsMiddleware(mux))
 	if err != nil {
 		log.Fatal("ListenAndServe: ", err)
 	}
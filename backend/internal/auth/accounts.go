@@ -0,0 +1,170 @@
+// Package auth manages user accounts: registration and login with hashed
+// passwords, and the signed tokens that authenticate websocket connections.
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"game-server/internal/storage"
+	"game-server/internal/types"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 128
+)
+
+// Failed logins allowed within loginWindow, per account name and per client
+// address, before further attempts are refused
+const (
+	loginFailuresPerName    = 5
+	loginFailuresPerAddress = 20
+	loginWindow             = 15 * time.Minute
+)
+
+var (
+	ErrInvalidName        = errors.New("names are 3 to 20 letters, digits, '_' or '-'")
+	ErrInvalidPassword    = fmt.Errorf("passwords are %d to %d characters", minPasswordLength, maxPasswordLength)
+	ErrNameTaken          = errors.New("name already taken")
+	ErrInvalidCredentials = errors.New("invalid name or password")
+	ErrTooManyAttempts    = errors.New("too many failed logins, try again later")
+)
+
+// namePattern matches the names accounts can be registered with
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,20}$`)
+
+// guestPrefix starts the names of guests, which accounts cannot take
+const guestPrefix = "guest-"
+
+// dummyHash is checked against the password of logins to unknown accounts, so
+// that they take as long as logins to existing ones
+var dummyHash = sync.OnceValues(func() (string, error) {
+	return HashPassword("")
+})
+
+// Accounts registers and logs in users
+type Accounts struct {
+	store  storage.Store
+	tokens *Tokens
+	// Failed logins, by lower case account name and by client address
+	failuresByName    *Throttle
+	failuresByAddress *Throttle
+}
+
+func NewAccounts(store storage.Store, tokens *Tokens) *Accounts {
+	return &Accounts{
+		store:             store,
+		tokens:            tokens,
+		failuresByName:    NewThrottle(loginFailuresPerName, loginWindow),
+		failuresByAddress: NewThrottle(loginFailuresPerAddress, loginWindow),
+	}
+}
+
+// Register creates an account and returns a token for it
+func (a *Accounts) Register(credentials types.Credentials) (types.AuthResponse, error) {
+	if !namePattern.MatchString(credentials.Name) || strings.HasPrefix(strings.ToLower(credentials.Name), guestPrefix) {
+		return types.AuthResponse{}, ErrInvalidName
+	}
+	if length := utf8.RuneCountInString(credentials.Password); length < minPasswordLength || length > maxPasswordLength {
+		return types.AuthResponse{}, ErrInvalidPassword
+	}
+
+	hash, err := HashPassword(credentials.Password)
+	if err != nil {
+		return types.AuthResponse{}, err
+	}
+	account := types.Account{
+		ID:           generateAccountID(),
+		Name:         credentials.Name,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+	}
+	if err := a.store.CreateAccount(account); err != nil {
+		if errors.Is(err, storage.ErrAccountExists) {
+			return types.AuthResponse{}, ErrNameTaken
+		}
+		return types.AuthResponse{}, err
+	}
+	return a.authenticate(account)
+}
+
+// Login checks the password of an account and returns a token for it. address
+// identifies the client logging in: an account name or an address that failed
+// too many logins recently is refused without checking the password.
+func (a *Accounts) Login(credentials types.Credentials, address string) (types.AuthResponse, error) {
+	name := strings.ToLower(credentials.Name)
+	now := time.Now()
+	if !a.failuresByName.Allow(name, now) || !a.failuresByAddress.Allow(address, now) {
+		return types.AuthResponse{}, ErrTooManyAttempts
+	}
+
+	account, err := a.checkCredentials(credentials)
+	if errors.Is(err, ErrInvalidCredentials) {
+		a.failuresByName.Fail(name, now)
+		a.failuresByAddress.Fail(address, now)
+	}
+	if err != nil {
+		return types.AuthResponse{}, err
+	}
+	a.failuresByName.Reset(name)
+	return a.authenticate(account)
+}
+
+// checkCredentials returns the account a name and password log in to
+func (a *Accounts) checkCredentials(credentials types.Credentials) (types.Account, error) {
+	if len(credentials.Password) > 4*maxPasswordLength {
+		return types.Account{}, ErrInvalidCredentials
+	}
+	account, exists, err := a.store.GetAccount(credentials.Name)
+	if err != nil {
+		return types.Account{}, err
+	}
+	if !exists {
+		hash, err := dummyHash()
+		if err != nil {
+			return types.Account{}, err
+		}
+		CheckPassword(credentials.Password, hash)
+		return types.Account{}, ErrInvalidCredentials
+	}
+
+	valid, err := CheckPassword(credentials.Password, account.PasswordHash)
+	if err != nil {
+		return types.Account{}, fmt.Errorf("account %s: %w", account.Name, err)
+	}
+	if !valid {
+		return types.Account{}, ErrInvalidCredentials
+	}
+	return account, nil
+}
+
+// Authenticate returns the user a token was issued for
+func (a *Accounts) Authenticate(token string) (types.User, error) {
+	return a.tokens.Verify(token, time.Now())
+}
+
+// authenticate issues a token for an account
+func (a *Accounts) authenticate(account types.Account) (types.AuthResponse, error) {
+	token, expiresAt, err := a.tokens.Issue(account, time.Now())
+	if err != nil {
+		return types.AuthResponse{}, fmt.Errorf("failed to issue token: %w", err)
+	}
+	return types.AuthResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		User:      types.User{ID: account.ID, Name: account.Name},
+	}, nil
+}
+
+func generateAccountID() string {
+	bytes := make([]byte, 8)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"errors"
+	"game-server/internal/storage"
+	"game-server/internal/types"
+	"testing"
+	"time"
+)
+
+func TestLogin(t *testing.T) {
+	accounts := NewAccounts(storage.NewMemoryStore(), NewTokens([]byte("secret")))
+	if _, err := accounts.Register(types.Credentials{Name: "Alice", Password: "correct horse"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	response, err := accounts.Login(types.Credentials{Name: "alice", Password: "correct horse"}, "10.0.0.1")
+	if err != nil || response.User.Name != "Alice" || response.Token == "" {
+		t.Errorf("Login = %+v, %v", response, err)
+	}
+	for _, credentials := range []types.Credentials{
+		{Name: "alice", Password: "wrong password"},
+		{Name: "bob", Password: "correct horse"},
+	} {
+		if _, err := accounts.Login(credentials, "10.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Login(%s, %s) = %v, want ErrInvalidCredentials", credentials.Name, credentials.Password, err)
+		}
+	}
+}
+
+func TestLoginIsThrottled(t *testing.T) {
+	accounts := NewAccounts(storage.NewMemoryStore(), NewTokens([]byte("secret")))
+	accounts.failuresByName = NewThrottle(2, time.Minute)
+	accounts.failuresByAddress = NewThrottle(3, time.Minute)
+	if _, err := accounts.Register(types.Credentials{Name: "Alice", Password: "correct horse"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	wrong := types.Credentials{Name: "ALICE", Password: "wrong password"}
+	right := types.Credentials{Name: "alice", Password: "correct horse"}
+
+	// The name is refused once it failed too often, even with the right password
+	for i := 0; i < 2; i++ {
+		accounts.Login(wrong, "10.0.0.1")
+	}
+	if _, err := accounts.Login(right, "10.0.0.2"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("Login after failures on the name = %v, want ErrTooManyAttempts", err)
+	}
+
+	// So is an address trying many names
+	if _, err := accounts.Login(types.Credentials{Name: "bob", Password: "guess"}, "10.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Login(bob) = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := accounts.Login(types.Credentials{Name: "carol", Password: "guess"}, "10.0.0.1"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("Login after failures from the address = %v, want ErrTooManyAttempts", err)
+	}
+}
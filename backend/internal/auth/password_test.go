@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestPBKDF2(t *testing.T) {
+	// PBKDF2-HMAC-SHA256 test vectors of RFC 7914, section 11
+	tests := []struct {
+		password   string
+		salt       string
+		iterations int
+		want       string
+	}{
+		{
+			password:   "passwd",
+			salt:       "salt",
+			iterations: 1,
+			want: "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+				"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783",
+		},
+		{
+			password:   "Password",
+			salt:       "NaCl",
+			iterations: 80000,
+			want: "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56" +
+				"a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			key := pbkdf2([]byte(tt.password), []byte(tt.salt), tt.iterations, 64)
+			if got := hex.EncodeToString(key); got != tt.want {
+				t.Errorf("pbkdf2(%q, %q, %d) = %s, want %s", tt.password, tt.salt, tt.iterations, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	if ok, err := CheckPassword("correct horse", hash); err != nil || !ok {
+		t.Errorf("CheckPassword(right password) = %v, %v, want true", ok, err)
+	}
+	if ok, err := CheckPassword("wrong horse", hash); err != nil || ok {
+		t.Errorf("CheckPassword(wrong password) = %v, %v, want false", ok, err)
+	}
+	for _, invalid := range []string{"", "bcrypt$1$c2FsdA$a2V5", "pbkdf2-sha256$0$c2FsdA$a2V5", "pbkdf2-sha256$1$!$a2V5", "pbkdf2-sha256$1$c2FsdA$"} {
+		if _, err := CheckPassword("correct horse", invalid); err == nil {
+			t.Errorf("CheckPassword accepted the hash %q", invalid)
+		}
+	}
+}
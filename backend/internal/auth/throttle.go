@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// maxThrottledKeys bounds the keys a throttle tracks before it forgets the
+// ones whose failures all expired
+const maxThrottledKeys = 10000
+
+// Throttle counts the failed attempts made with a key, such as an account name
+// or a client address, and refuses the key once it failed too often recently
+type Throttle struct {
+	limit    int
+	window   time.Duration
+	mutex    sync.Mutex
+	failures map[string][]time.Time
+}
+
+// NewThrottle returns a throttle allowing limit failures per key within window
+func NewThrottle(limit int, window time.Duration) *Throttle {
+	return &Throttle{
+		limit:    limit,
+		window:   window,
+		failures: make(map[string][]time.Time),
+	}
+}
+
+// Allow reports whether a key can be tried at now
+func (t *Throttle) Allow(key string, now time.Time) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return len(t.recentFailures(key, now)) < t.limit
+}
+
+// Fail records a failed attempt with a key
+func (t *Throttle) Fail(key string, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if len(t.failures) >= maxThrottledKeys {
+		for other := range t.failures {
+			t.recentFailures(other, now)
+		}
+	}
+	t.failures[key] = append(t.recentFailures(key, now), now)
+}
+
+// Reset forgets the failures of a key, after it succeeded
+func (t *Throttle) Reset(key string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.failures, key)
+}
+
+// recentFailures drops the expired failures of a key and returns the others,
+// oldest first. The caller must hold the mutex.
+func (t *Throttle) recentFailures(key string, now time.Time) []time.Time {
+	failures := t.failures[key]
+	expired := 0
+	for expired < len(failures) && !failures[expired].Add(t.window).After(now) {
+		expired++
+	}
+	if expired == len(failures) {
+		delete(t.failures, key)
+		return nil
+	}
+	failures = failures[expired:]
+	t.failures[key] = failures
+	return failures
+}
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	throttle := NewThrottle(2, time.Minute)
+	start := time.Now()
+
+	throttle.Fail("alice", start)
+	if !throttle.Allow("alice", start) {
+		t.Fatal("refused a key after one failure of two allowed")
+	}
+	throttle.Fail("alice", start.Add(10*time.Second))
+	if throttle.Allow("alice", start.Add(20*time.Second)) {
+		t.Error("allowed a key past its failure limit")
+	}
+	if !throttle.Allow("bob", start.Add(20*time.Second)) {
+		t.Error("the failures of a key refused another")
+	}
+
+	// Failures expire one by one once the window has passed
+	if !throttle.Allow("alice", start.Add(time.Minute)) {
+		t.Error("refused a key once its oldest failure expired")
+	}
+	throttle.Fail("alice", start.Add(time.Minute))
+	if throttle.Allow("alice", start.Add(time.Minute)) {
+		t.Error("allowed a key that failed again within the window")
+	}
+
+	throttle.Reset("alice")
+	if !throttle.Allow("alice", start.Add(time.Minute)) {
+		t.Error("refused a key after it was reset")
+	}
+}
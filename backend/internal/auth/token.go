@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"game-server/internal/types"
+	"strings"
+	"time"
+)
+
+// TokenTTL is how long a token can be used after it was issued
+const TokenTTL = 7 * 24 * time.Hour
+
+// ErrInvalidToken is returned for tokens that are malformed, forged or expired
+var ErrInvalidToken = errors.New("invalid token")
+
+// claims are the payload of a token
+type claims struct {
+	UserID    string `json:"sub"`
+	UserName  string `json:"name"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Tokens issues and verifies the tokens of logged in users. A token is its
+// base64url encoded claims and their HMAC-SHA256 signature, joined by a dot.
+type Tokens struct {
+	secret []byte
+}
+
+func NewTokens(secret []byte) *Tokens {
+	return &Tokens{secret: secret}
+}
+
+// Issue returns a token for an account and the time it expires at
+func (t *Tokens) Issue(account types.Account, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(TokenTTL)
+	payload, err := json.Marshal(claims{
+		UserID:    account.ID,
+		UserName:  account.Name,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(t.sign(encoded)), expiresAt, nil
+}
+
+// Verify returns the user a token was issued for
+func (t *Tokens) Verify(token string, now time.Time) (types.User, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return types.User{}, ErrInvalidToken
+	}
+	decodedSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decodedSignature, t.sign(encoded)) {
+		return types.User{}, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return types.User{}, ErrInvalidToken
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil || c.UserID == "" {
+		return types.User{}, ErrInvalidToken
+	}
+	if now.Unix() >= c.ExpiresAt {
+		return types.User{}, ErrInvalidToken
+	}
+	return types.User{ID: c.UserID, Name: c.UserName}, nil
+}
+
+// sign returns the signature of the encoded claims of a token
+func (t *Tokens) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"game-server/internal/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testAccount = types.Account{ID: "user-1", Name: "Alice"}
+
+func TestVerifyIssuedToken(t *testing.T) {
+	tokens := NewTokens([]byte("secret"))
+	now := time.Now()
+	token, expiresAt, err := tokens.Issue(testAccount, now)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if !expiresAt.Equal(now.Add(TokenTTL)) {
+		t.Errorf("token expires at %v, want %v", expiresAt, now.Add(TokenTTL))
+	}
+
+	user, err := tokens.Verify(token, now.Add(TokenTTL-time.Second))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if user.ID != testAccount.ID || user.Name != testAccount.Name {
+		t.Errorf("Verify = %+v, want %s (%s)", user, testAccount.ID, testAccount.Name)
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	tokens := NewTokens([]byte("secret"))
+	now := time.Now()
+	token, _, err := tokens.Issue(testAccount, now)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	encoded, signature, _ := strings.Cut(token, ".")
+
+	// Claims for another user, signed with the original signature
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin","name":"Admin","exp":9999999999}`)) + "." + signature
+	// The same claims signed with another secret
+	otherSecret, _, _ := NewTokens([]byte("other secret")).Issue(testAccount, now)
+
+	tests := []struct {
+		name  string
+		token string
+		now   time.Time
+	}{
+		{"expired", token, now.Add(TokenTTL)},
+		{"tampered claims", forged, now},
+		{"tampered signature", encoded + "." + base64.RawURLEncoding.EncodeToString([]byte("signature")), now},
+		{"other secret", otherSecret, now},
+		{"no signature", encoded, now},
+		{"empty", "", now},
+		{"not base64", "!!!." + signature, now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tokens.Verify(tt.token, tt.now); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
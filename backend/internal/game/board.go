@@ -0,0 +1,117 @@
+package game
+
+import (
+	"fmt"
+	"game-server/internal/types"
+	"sort"
+)
+
+// Cell symbols of a map file
+const (
+	symbolNone     = ' '
+	symbolFloor    = '.'
+	symbolObstacle = '#'
+	symbolHole     = 'o'
+)
+
+var ErrInvalidPlacement = &RuleError{Code: types.ReasonInvalidPlacement, Message: "cell is not one of the offered placement cells"}
+
+type cellKind int
+
+const (
+	cellFloor cellKind = iota
+	// An obstacle blocks both movement and line of sight
+	cellObstacle
+	// A hole blocks movement only
+	cellHole
+)
+
+// Board is the playing area of a game, built from a map layout.
+// Movement, targeting and placement all read the same board.
+type Board struct {
+	layout     types.BoardMap
+	cells      map[types.Position]cellKind
+	placements map[string][]types.Position
+}
+
+// NewBoard builds a board from a map layout. Row i of the layout holds the cells
+// with y = Origin.Y + i, its column j the cell with x = Origin.X + j.
+func NewBoard(layout types.BoardMap) (*Board, error) {
+	board := &Board{
+		layout:     layout,
+		cells:      make(map[types.Position]cellKind),
+		placements: make(map[string][]types.Position),
+	}
+
+	for i, row := range layout.Rows {
+		for j, symbol := range []byte(row) {
+			pos := types.Position{X: layout.Origin.X + j, Y: layout.Origin.Y + i}
+			switch {
+			case symbol == symbolNone:
+				continue
+			case symbol == symbolFloor:
+				board.cells[pos] = cellFloor
+			case symbol == symbolObstacle:
+				board.cells[pos] = cellObstacle
+			case symbol == symbolHole:
+				board.cells[pos] = cellHole
+			case symbol >= 'A' && symbol <= 'Z':
+				// Placement cells are floor cells reserved to a team
+				board.cells[pos] = cellFloor
+				group := string(symbol)
+				board.placements[group] = append(board.placements[group], pos)
+			default:
+				return nil, fmt.Errorf("unknown cell %q at row %d, column %d", symbol, i, j)
+			}
+		}
+	}
+
+	if len(board.cells) == 0 {
+		return nil, fmt.Errorf("map has no cells")
+	}
+	// Fights oppose teams, and every team needs cells to be placed on
+	if len(board.placements) < 2 {
+		return nil, fmt.Errorf("map needs placement cells for at least two teams")
+	}
+	return board, nil
+}
+
+// Layout returns the map the board was built from, as sent to clients
+func (b *Board) Layout() *types.BoardMap {
+	return &b.layout
+}
+
+// Contains reports whether a position is a cell of the board, whatever its kind
+func (b *Board) Contains(pos types.Position) bool {
+	_, exists := b.cells[pos]
+	return exists
+}
+
+// IsWalkable reports whether a character can stand on a position
+func (b *Board) IsWalkable(pos types.Position) bool {
+	kind, exists := b.cells[pos]
+	return exists && kind == cellFloor
+}
+
+// BlocksLineOfSight reports whether a position holds an obstacle
+func (b *Board) BlocksLineOfSight(pos types.Position) bool {
+	kind, exists := b.cells[pos]
+	return exists && kind == cellObstacle
+}
+
+// PlacementGroups returns the names of the placement groups of the board, in order
+func (b *Board) PlacementGroups() []string {
+	groups := make([]string, 0, len(b.placements))
+	for group := range b.placements {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// PlacementCells returns the cells where the characters of a group are placed
+func (b *Board) PlacementCells(group string) []types.Position {
+	cells := make([]types.Position, len(b.placements[group]))
+	copy(cells, b.placements[group])
+	return cells
+}
@@ -0,0 +1,77 @@
+package game
+
+import (
+	"game-server/internal/types"
+	"testing"
+)
+
+// mustBoard builds a board from rows starting at the origin
+func mustBoard(t *testing.T, rows ...string) *Board {
+	t.Helper()
+	board, err := NewBoard(types.BoardMap{ID: "test", Rows: rows})
+	if err != nil {
+		t.Fatalf("failed to build board: %v", err)
+	}
+	return board
+}
+
+func TestNewBoardRejectsInvalidMaps(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []string
+	}{
+		{"unknown cell", []string{"A.x.B"}},
+		{"no cells", []string{"   "}},
+		{"no placement cells", []string{"....."}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewBoard(types.BoardMap{Rows: test.rows}); err == nil {
+				t.Error("NewBoard accepted the map")
+			}
+		})
+	}
+}
+
+func TestBoardCells(t *testing.T) {
+	board, err := NewBoard(types.BoardMap{Origin: types.Position{X: -1, Y: -1}, Rows: []string{
+		"A#o",
+		" .B",
+	}})
+	if err != nil {
+		t.Fatalf("failed to build board: %v", err)
+	}
+
+	tests := []struct {
+		name                                  string
+		position                              types.Position
+		contains, walkable, blocksLineOfSight bool
+	}{
+		{"placement cell", types.Position{X: -1, Y: -1}, true, true, false},
+		{"obstacle", types.Position{X: 0, Y: -1}, true, false, true},
+		{"hole", types.Position{X: 1, Y: -1}, true, false, false},
+		{"no cell", types.Position{X: -1, Y: 0}, false, false, false},
+		{"floor", types.Position{X: 0, Y: 0}, true, true, false},
+		{"off the map", types.Position{X: 5, Y: 5}, false, false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := board.Contains(test.position); got != test.contains {
+				t.Errorf("Contains = %t, want %t", got, test.contains)
+			}
+			if got := board.IsWalkable(test.position); got != test.walkable {
+				t.Errorf("IsWalkable = %t, want %t", got, test.walkable)
+			}
+			if got := board.BlocksLineOfSight(test.position); got != test.blocksLineOfSight {
+				t.Errorf("BlocksLineOfSight = %t, want %t", got, test.blocksLineOfSight)
+			}
+		})
+	}
+
+	if groups := board.PlacementGroups(); len(groups) != 2 || groups[0] != "A" || groups[1] != "B" {
+		t.Errorf("PlacementGroups = %v, want [A B]", groups)
+	}
+	if cells := board.PlacementCells("B"); len(cells) != 1 || cells[0] != (types.Position{X: 1, Y: 0}) {
+		t.Errorf("PlacementCells(B) = %v, want [{1 0}]", cells)
+	}
+}
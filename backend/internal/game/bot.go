@@ -0,0 +1,229 @@
+package game
+
+import (
+	"game-server/internal/types"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// Bot difficulty levels
+const (
+	// BotEasy casts a random useful spell from where it stands
+	BotEasy = "easy"
+	// BotMedium casts the most damaging spell from where it stands
+	BotMedium = "medium"
+	// BotHard also considers every cell it can walk to before casting
+	BotHard = "hard"
+)
+
+// Bot action kinds, named after the matching client messages
+const (
+	BotMove      = "move"
+	BotCastSpell = "cast_spell"
+	BotEndTurn   = "end_turn"
+)
+
+var ErrUnknownDifficulty = &RuleError{Code: types.ReasonUnknownDifficulty, Message: "unknown bot difficulty"}
+
+// killBonus is added to the score of a cast for each enemy it kills
+const killBonus = 50
+
+// IsBotDifficulty reports whether a difficulty level exists
+func IsBotDifficulty(difficulty string) bool {
+	switch difficulty {
+	case BotEasy, BotMedium, BotHard:
+		return true
+	}
+	return false
+}
+
+// BotAction is the next action a bot takes during its turn
+type BotAction struct {
+	Kind    string
+	Target  types.Position
+	SpellID int
+}
+
+// botCast is a cast a bot could make, with the score of its expected outcome
+type botCast struct {
+	from    types.Position
+	cost    int // movement points spent to reach from
+	spellID int
+	target  types.Position
+	score   int
+}
+
+// PlanBotAction chooses the next action of a bot whose turn it is. Bots cast
+// spells that hurt the other teams more than their own, walk towards the nearest
+// enemy when they have nothing to cast, and end their turn otherwise.
+func (gm *GameManager) PlanBotAction(botID string, difficulty string, rng *rand.Rand) BotAction {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	currentState := gm.fold.state
+	bot, exists := currentState.Players[botID]
+	if !exists || bot.Character == nil || bot.Character.Position == nil || !bot.Character.IsAlive {
+		return BotAction{Kind: BotEndTurn}
+	}
+	position := *bot.Character.Position
+
+	// Hard bots plan their cast from every cell they can walk to
+	origins := map[types.Position]int{position: 0}
+	if difficulty == BotHard {
+		occupied := gm.occupiedCells(botID)
+		reachable := ReachableCells(gm.board, position, bot.Character.MovementPoints, func(pos types.Position) bool {
+			return occupied[pos]
+		})
+		for cell, cost := range reachable {
+			origins[cell] = cost
+		}
+	}
+
+	casts := gm.botCasts(bot, origins)
+	if len(casts) > 0 {
+		chosen := casts[0]
+		if difficulty == BotEasy {
+			chosen = casts[rng.Intn(len(casts))]
+		}
+		if chosen.from != position {
+			return BotAction{Kind: BotMove, Target: chosen.from}
+		}
+		return BotAction{Kind: BotCastSpell, SpellID: chosen.spellID, Target: chosen.target}
+	}
+
+	if target, ok := gm.botApproach(bot); ok {
+		return BotAction{Kind: BotMove, Target: target}
+	}
+	return BotAction{Kind: BotEndTurn}
+}
+
+// botCasts lists the casts with a positive score the bot can make from each origin,
+// best first. The caller must hold the mutex.
+func (gm *GameManager) botCasts(bot types.Player, origins map[types.Position]int) []botCast {
+	currentState := gm.fold.state
+
+	var casts []botCast
+	for spellID, spell := range currentState.Spells {
+		if spell.APCost > bot.Character.ActionPoints {
+			continue
+		}
+		if spellUsageError(bot.Character, spellID, spell) != nil {
+			continue
+		}
+		id, _ := strconv.Atoi(spellID)
+
+		for from, cost := range origins {
+			for dx := -spell.Range; dx <= spell.Range; dx++ {
+				for dy := abs(dx) - spell.Range; dy <= spell.Range-abs(dx); dy++ {
+					target := types.Position{X: from.X + dx, Y: from.Y + dy}
+					if gm.checkSpellTargetFrom(bot.UserID, spell, from, target) != nil {
+						continue
+					}
+					score := gm.scoreBotCast(bot, spell, from, target)
+					if score > 0 {
+						casts = append(casts, botCast{from: from, cost: cost, spellID: id, target: target, score: score})
+					}
+				}
+			}
+		}
+	}
+
+	// Best score first, then the cheapest move, then a fixed order so that bots are predictable
+	sort.Slice(casts, func(i, j int) bool {
+		a, b := casts[i], casts[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.cost != b.cost {
+			return a.cost < b.cost
+		}
+		if a.spellID != b.spellID {
+			return a.spellID < b.spellID
+		}
+		if a.from != b.from {
+			return a.from.X < b.from.X || (a.from.X == b.from.X && a.from.Y < b.from.Y)
+		}
+		return a.target.X < b.target.X || (a.target.X == b.target.X && a.target.Y < b.target.Y)
+	})
+	return casts
+}
+
+// scoreBotCast rates a cast by the damage it is expected to deal to enemies, minus
+// twice the damage dealt to the bot's team when friendly fire is on.
+// The caller must hold the mutex.
+func (gm *GameManager) scoreBotCast(bot types.Player, spell types.Spell, from, target types.Position) int {
+	currentState := gm.fold.state
+
+	score := 0
+	for _, position := range affectedPositions(spell, target, from) {
+		for userID, player := range currentState.Players {
+			if player.Character == nil || !player.Character.IsAlive || player.Character.Position == nil {
+				continue
+			}
+			playerPosition := *player.Character.Position
+			if userID == bot.UserID {
+				playerPosition = from
+			}
+			if playerPosition != position {
+				continue
+			}
+
+			dealt := min(damageTaken(player.Character, spell.Damage), player.Character.Health)
+			switch {
+			case player.Team != bot.Team:
+				score += dealt
+				if dealt >= player.Character.Health {
+					score += killBonus
+				}
+			case gm.friendlyFire:
+				score -= 2 * dealt
+			}
+		}
+	}
+	return score
+}
+
+// botApproach returns the reachable cell closest to the nearest enemy, if it is
+// closer than the bot already is. The caller must hold the mutex.
+func (gm *GameManager) botApproach(bot types.Player) (types.Position, bool) {
+	currentState := gm.fold.state
+
+	var enemies []types.Position
+	for _, player := range currentState.Players {
+		if player.Team != bot.Team && player.Character != nil && player.Character.IsAlive && player.Character.Position != nil {
+			enemies = append(enemies, *player.Character.Position)
+		}
+	}
+	if len(enemies) == 0 {
+		return types.Position{}, false
+	}
+
+	distanceToEnemies := func(pos types.Position) int {
+		closest := -1
+		for _, enemy := range enemies {
+			if d := manhattanDistance(pos, enemy); closest < 0 || d < closest {
+				closest = d
+			}
+		}
+		return closest
+	}
+
+	occupied := gm.occupiedCells(bot.UserID)
+	reachable := ReachableCells(gm.board, *bot.Character.Position, bot.Character.MovementPoints, func(pos types.Position) bool {
+		return occupied[pos]
+	})
+
+	best := *bot.Character.Position
+	bestDistance := distanceToEnemies(best)
+	bestCost := 0
+	for cell, cost := range reachable {
+		d := distanceToEnemies(cell)
+		better := d < bestDistance || (d == bestDistance && cost < bestCost) ||
+			(d == bestDistance && cost == bestCost && (cell.X < best.X || (cell.X == best.X && cell.Y < best.Y)))
+		if better {
+			best, bestDistance, bestCost = cell, d, cost
+		}
+	}
+	return best, best != *bot.Character.Position
+}
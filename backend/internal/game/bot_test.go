@@ -0,0 +1,104 @@
+package game
+
+import (
+	"game-server/internal/types"
+	"math/rand"
+	"testing"
+)
+
+// A short range jab and a longer range blast that deals more damage
+const botSpells = `[
+	{"id": 1, "name": "Jab", "APCost": 2, "range": 1, "damage": 10, "areaOfEffect": "none", "type": "Melee"},
+	{"id": 2, "name": "Blast", "APCost": 3, "range": 3, "damage": 30, "areaOfEffect": "none", "type": "Fire"}
+]`
+
+// newBotFight starts a fight between alice, of team A, and bob, of team B, on a
+// board with a single placement cell per team
+func newBotFight(t *testing.T, rows ...string) *GameManager {
+	t.Helper()
+	spells, err := ParseSpellCatalogue([]byte(botSpells))
+	if err != nil {
+		t.Fatalf("failed to parse spells: %v", err)
+	}
+	gm := NewGameManager(spells, mustBoard(t, rows...))
+	startTestFight(t, gm, readyPlayers())
+	return gm
+}
+
+func TestPlanBotAction(t *testing.T) {
+	tests := []struct {
+		name       string
+		rows       []string
+		difficulty string
+		want       BotAction
+	}{
+		{"medium casts its most damaging spell", []string{"AB"}, BotMedium, BotAction{Kind: BotCastSpell, SpellID: 2, Target: types.Position{X: 1, Y: 0}}},
+		{"medium casts the spell in range", []string{"A..B"}, BotMedium, BotAction{Kind: BotCastSpell, SpellID: 2, Target: types.Position{X: 3, Y: 0}}},
+		{"medium walks to the enemy out of range", []string{"A.....B"}, BotMedium, BotAction{Kind: BotMove, Target: types.Position{X: 4, Y: 0}}},
+		{"easy walks to the enemy out of range", []string{"A.....B"}, BotEasy, BotAction{Kind: BotMove, Target: types.Position{X: 4, Y: 0}}},
+		{"hard walks to the nearest cell to cast from", []string{"A.....B"}, BotHard, BotAction{Kind: BotMove, Target: types.Position{X: 3, Y: 0}}},
+		{"hard casts from where it stands", []string{"A..B"}, BotHard, BotAction{Kind: BotCastSpell, SpellID: 2, Target: types.Position{X: 3, Y: 0}}},
+		{"casts over an obstacle without line of sight", []string{"A#B"}, BotHard, BotAction{Kind: BotCastSpell, SpellID: 2, Target: types.Position{X: 2, Y: 0}}},
+		{"no way to the enemy", []string{"A#######B"}, BotMedium, BotAction{Kind: BotEndTurn}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gm := newBotFight(t, test.rows...)
+			if got := gm.PlanBotAction("alice", test.difficulty, rand.New(rand.NewSource(1))); got != test.want {
+				t.Errorf("PlanBotAction = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEasyBotPicksAmongUsefulCasts(t *testing.T) {
+	gm := newBotFight(t, "AB")
+	enemy := types.Position{X: 1, Y: 0}
+
+	picked := make(map[int]bool)
+	for seed := int64(1); seed <= 20; seed++ {
+		action := gm.PlanBotAction("alice", BotEasy, rand.New(rand.NewSource(seed)))
+		if action.Kind != BotCastSpell || action.Target != enemy {
+			t.Fatalf("seed %d: PlanBotAction = %+v, want a cast on %v", seed, action, enemy)
+		}
+		picked[action.SpellID] = true
+
+		// The same seed makes the same pick
+		if again := gm.PlanBotAction("alice", BotEasy, rand.New(rand.NewSource(seed))); again != action {
+			t.Errorf("seed %d: PlanBotAction = %+v then %+v", seed, action, again)
+		}
+	}
+	if !picked[1] || !picked[2] {
+		t.Errorf("easy bots picked the spells %v, want both", picked)
+	}
+}
+
+func TestBotApproachMovesTowardsTheNearestEnemy(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []string
+		want types.Position
+		ok   bool
+	}{
+		{"straight line", []string{"A......B"}, types.Position{X: 4, Y: 0}, true},
+		{"around an obstacle", []string{"A#..B", "....."}, types.Position{X: 2, Y: 0}, true},
+		{"over a hole", []string{"AoooB"}, types.Position{}, false},
+		{"already next to the enemy", []string{"AB"}, types.Position{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gm := newBotFight(t, test.rows...)
+			state := gm.GetCurrentState()
+			bot, enemy := state.Players["alice"], *state.Players["bob"].Character.Position
+			before := manhattanDistance(*bot.Character.Position, enemy)
+
+			got, ok := gm.botApproach(bot)
+			if ok != test.ok || (ok && got != test.want) {
+				t.Fatalf("botApproach = %v, %t, want %v, %t", got, ok, test.want, test.ok)
+			}
+			if ok && manhattanDistance(got, enemy) >= before {
+				t.Errorf("%v is no closer to the enemy than %v", got, *bot.Character.Position)
+			}
+		})
+	}
+}
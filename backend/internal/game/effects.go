@@ -0,0 +1,137 @@
+package game
+
+import (
+	"fmt"
+	"game-server/internal/types"
+	"log"
+)
+
+// Status effect kinds
+const (
+	// EffectPoison deals Value damage at the start of each of the character's turns
+	EffectPoison = "poison"
+	// EffectMovementPoints adds Value MP (negative to remove) at the start of each turn
+	EffectMovementPoints = "movement_points"
+	// EffectActionPoints adds Value AP (negative to remove) at the start of each turn
+	EffectActionPoints = "action_points"
+	// EffectVulnerability increases the damage the character takes, from spells and
+	// poison alike, by Value percent
+	EffectVulnerability = "vulnerability"
+)
+
+// Stack policies, deciding what happens when an effect hits a character that
+// already carries the same effect from the same spell and caster
+const (
+	// StackRefresh replaces the existing effect, restarting its duration
+	StackRefresh = "refresh"
+	// StackAdd keeps the existing effect and adds the new one next to it
+	StackAdd = "stack"
+	// StackIgnore keeps the existing effect and drops the new one
+	StackIgnore = "ignore"
+)
+
+var knownEffectKinds = map[string]bool{
+	EffectPoison:         true,
+	EffectMovementPoints: true,
+	EffectActionPoints:   true,
+	EffectVulnerability:  true,
+}
+
+var knownStackPolicies = map[string]bool{
+	"":           true, // defaults to StackRefresh
+	StackRefresh: true,
+	StackAdd:     true,
+	StackIgnore:  true,
+}
+
+// validateSpellEffect checks an effect definition from the spell catalogue
+func validateSpellEffect(effect types.SpellEffect) error {
+	if !knownEffectKinds[effect.Kind] {
+		return fmt.Errorf("unknown effect kind %q", effect.Kind)
+	}
+	if !knownStackPolicies[effect.StackPolicy] {
+		return fmt.Errorf("unknown stack policy %q", effect.StackPolicy)
+	}
+	if effect.Duration <= 0 {
+		return fmt.Errorf("effect %q must last at least one turn", effect.Kind)
+	}
+	return nil
+}
+
+// addStatusEffect attaches an effect to a character according to its stack policy
+func addStatusEffect(character *types.Character, effect types.StatusEffect) {
+	for i, existing := range character.Effects {
+		if existing.Kind != effect.Kind || existing.SpellID != effect.SpellID || existing.SourceUserID != effect.SourceUserID {
+			continue
+		}
+		switch effect.StackPolicy {
+		case StackAdd:
+			continue
+		case StackIgnore:
+			return
+		default:
+			character.Effects[i] = effect
+			return
+		}
+	}
+	character.Effects = append(character.Effects, effect)
+}
+
+// damageTaken returns the damage a character takes from a hit, once its
+// vulnerabilities are applied
+func damageTaken(character *types.Character, damage int) int {
+	bonus := 0
+	for _, effect := range character.Effects {
+		if effect.Kind == EffectVulnerability {
+			bonus += effect.Value
+		}
+	}
+	return damage * (100 + bonus) / 100
+}
+
+// ApplyTurnStartEffects applies the effects of a player's character at the start of
+// its turn: poison damage and AP/MP changes, on top of the restored AP and MP
+func (gm *GameManager) ApplyTurnStartEffects(playerID string) error {
+	gm.mutex.Lock()
+	defer gm.mutex.Unlock()
+
+	player, exists := gm.fold.state.Players[playerID]
+	if !exists || player.Character == nil {
+		return ErrPlayerNotFound
+	}
+
+	character := player.Character
+	for _, effect := range append([]types.StatusEffect(nil), character.Effects...) {
+		switch effect.Kind {
+		case EffectPoison:
+			// Vulnerabilities make poison hurt more, as they do spell hits
+			damage := damageTaken(character, effect.Value)
+			gm.record(&DamageApplied{SourceID: effect.SourceUserID, TargetID: playerID, Amount: damage})
+			log.Printf("[Debug] Poison deals %d damage to player %s (health: %d)", damage, playerID, character.Health)
+			if !character.IsAlive {
+				log.Printf("[Debug] Player %s is now dead.", playerID)
+			}
+		case EffectMovementPoints:
+			gm.record(&PointsChanged{UserID: playerID, MovementPoints: effect.Value})
+		case EffectActionPoints:
+			gm.record(&PointsChanged{UserID: playerID, ActionPoints: effect.Value})
+		}
+	}
+	return nil
+}
+
+// countEffectsDown counts the effects of a character down by one turn at the end
+// of its turn, removing the ones that expire
+func countEffectsDown(character *types.Character) {
+	remaining := make([]types.StatusEffect, 0, len(character.Effects))
+	for _, effect := range character.Effects {
+		effect.RemainingTurns--
+		if effect.RemainingTurns > 0 {
+			remaining = append(remaining, effect)
+		}
+	}
+	if len(remaining) == 0 {
+		remaining = nil
+	}
+	character.Effects = remaining
+}
@@ -0,0 +1,120 @@
+package game
+
+import (
+	"game-server/internal/types"
+	"reflect"
+	"testing"
+)
+
+func TestAddStatusEffect(t *testing.T) {
+	poison := types.StatusEffect{Kind: EffectPoison, Value: 5, RemainingTurns: 1, SourceUserID: "bob", SpellID: 3}
+	with := func(change func(effect *types.StatusEffect)) types.StatusEffect {
+		effect := poison
+		change(&effect)
+		return effect
+	}
+
+	tests := []struct {
+		name  string
+		added types.StatusEffect
+		want  []types.StatusEffect
+	}{
+		{
+			name:  "refresh replaces the effect",
+			added: with(func(e *types.StatusEffect) { e.RemainingTurns = 3; e.StackPolicy = StackRefresh }),
+			want:  []types.StatusEffect{with(func(e *types.StatusEffect) { e.RemainingTurns = 3; e.StackPolicy = StackRefresh })},
+		},
+		{
+			name:  "refresh by default",
+			added: with(func(e *types.StatusEffect) { e.RemainingTurns = 3 }),
+			want:  []types.StatusEffect{with(func(e *types.StatusEffect) { e.RemainingTurns = 3 })},
+		},
+		{
+			name:  "stack adds the effect",
+			added: with(func(e *types.StatusEffect) { e.RemainingTurns = 3; e.StackPolicy = StackAdd }),
+			want:  []types.StatusEffect{poison, with(func(e *types.StatusEffect) { e.RemainingTurns = 3; e.StackPolicy = StackAdd })},
+		},
+		{
+			name:  "ignore keeps the effect",
+			added: with(func(e *types.StatusEffect) { e.RemainingTurns = 3; e.StackPolicy = StackIgnore }),
+			want:  []types.StatusEffect{poison},
+		},
+		{
+			name:  "another spell adds its effect",
+			added: with(func(e *types.StatusEffect) { e.SpellID = 4 }),
+			want:  []types.StatusEffect{poison, with(func(e *types.StatusEffect) { e.SpellID = 4 })},
+		},
+		{
+			name:  "another caster adds its effect",
+			added: with(func(e *types.StatusEffect) { e.SourceUserID = "carol" }),
+			want:  []types.StatusEffect{poison, with(func(e *types.StatusEffect) { e.SourceUserID = "carol" })},
+		},
+		{
+			name:  "another kind adds its effect",
+			added: with(func(e *types.StatusEffect) { e.Kind = EffectVulnerability }),
+			want:  []types.StatusEffect{poison, with(func(e *types.StatusEffect) { e.Kind = EffectVulnerability })},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			character := &types.Character{Effects: []types.StatusEffect{poison}}
+			addStatusEffect(character, test.added)
+			if !reflect.DeepEqual(character.Effects, test.want) {
+				t.Errorf("effects = %+v, want %+v", character.Effects, test.want)
+			}
+		})
+	}
+}
+
+func TestCountEffectsDown(t *testing.T) {
+	character := &types.Character{Effects: []types.StatusEffect{
+		{Kind: EffectPoison, Value: 5, RemainingTurns: 1},
+		{Kind: EffectMovementPoints, Value: -1, RemainingTurns: 2},
+	}}
+
+	countEffectsDown(character)
+	want := []types.StatusEffect{{Kind: EffectMovementPoints, Value: -1, RemainingTurns: 1}}
+	if !reflect.DeepEqual(character.Effects, want) {
+		t.Fatalf("effects after a turn = %+v, want %+v", character.Effects, want)
+	}
+
+	countEffectsDown(character)
+	if character.Effects != nil {
+		t.Errorf("effects after two turns = %+v, want none", character.Effects)
+	}
+}
+
+func TestTurnStartEffects(t *testing.T) {
+	gm := newTestGameManager(t)
+	startTestFight(t, gm, readyPlayers())
+
+	gm.mutex.Lock()
+	for _, effect := range []types.StatusEffect{
+		{Kind: EffectPoison, Value: 10, RemainingTurns: 2, SourceUserID: "bob", SpellID: 1},
+		{Kind: EffectVulnerability, Value: 50, RemainingTurns: 2, SourceUserID: "bob", SpellID: 2},
+		{Kind: EffectMovementPoints, Value: -2, RemainingTurns: 1, SourceUserID: "bob", SpellID: 3},
+		{Kind: EffectActionPoints, Value: 1, RemainingTurns: 1, SourceUserID: "bob", SpellID: 4},
+	} {
+		gm.record(&EffectApplied{TargetID: "alice", Effect: effect})
+	}
+	gm.mutex.Unlock()
+
+	if err := gm.ApplyTurnStartEffects("alice"); err != nil {
+		t.Fatalf("ApplyTurnStartEffects: %v", err)
+	}
+
+	// Poison is increased by the vulnerability, as spell hits are
+	alice := gm.GetCurrentState().Players["alice"].Character
+	if alice.Health != DefaultHealth-15 {
+		t.Errorf("health = %d, want %d", alice.Health, DefaultHealth-15)
+	}
+	if alice.MovementPoints != DefaultMovementPoints-2 || alice.ActionPoints != DefaultActionPoints+1 {
+		t.Errorf("AP/MP = %d/%d, want %d/%d", alice.ActionPoints, alice.MovementPoints, DefaultActionPoints+1, DefaultMovementPoints-2)
+	}
+	if stats := gm.FightStats()["bob"]; stats.DamageDealt != 15 {
+		t.Errorf("bob dealt %d damage, want 15", stats.DamageDealt)
+	}
+	if err := gm.ApplyTurnStartEffects("nobody"); err != ErrPlayerNotFound {
+		t.Errorf("ApplyTurnStartEffects(nobody) = %v, want %v", err, ErrPlayerNotFound)
+	}
+}
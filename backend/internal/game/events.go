@@ -0,0 +1,424 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"game-server/internal/types"
+	"strconv"
+	"time"
+)
+
+// Event is a domain event of a game. The game state is the fold of every event
+// of the log, in order: applying the events of a log to an empty state rebuilds
+// the game exactly, random draws included, since events carry their outcomes.
+// Events are never changed once recorded, so apply copies what it keeps.
+type Event interface {
+	EventType() string
+	apply(f *fold)
+}
+
+// EventRecord is an entry of the event log
+type EventRecord struct {
+	Sequence int       `json:"sequence"`
+	Time     time.Time `json:"time"`
+	Event    Event     `json:"-"`
+}
+
+// eventFactories creates an empty event of each type, to decode event logs
+var eventFactories = map[string]func() Event{
+	"lobby_opened":            func() Event { return &LobbyOpened{} },
+	"game_started":            func() Event { return &GameStarted{} },
+	"initial_position_chosen": func() Event { return &InitialPositionChosen{} },
+	"characters_placed":       func() Event { return &CharactersPlaced{} },
+	"phase_changed":           func() Event { return &PhaseChanged{} },
+	"fight_started":           func() Event { return &FightStarted{} },
+	"turn_started":            func() Event { return &TurnStarted{} },
+	"character_moved":         func() Event { return &CharacterMoved{} },
+	"spell_cast":              func() Event { return &SpellCast{} },
+	"damage_applied":          func() Event { return &DamageApplied{} },
+	"effect_applied":          func() Event { return &EffectApplied{} },
+	"points_changed":          func() Event { return &PointsChanged{} },
+	"turn_ended":              func() Event { return &TurnEnded{} },
+}
+
+type eventRecordJSON struct {
+	Sequence int             `json:"sequence"`
+	Time     time.Time       `json:"time"`
+	Type     string          `json:"type"`
+	Data     json.RawMessage `json:"data"`
+}
+
+func (r EventRecord) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(r.Event)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(eventRecordJSON{
+		Sequence: r.Sequence,
+		Time:     r.Time,
+		Type:     r.Event.EventType(),
+		Data:     data,
+	})
+}
+
+func (r *EventRecord) UnmarshalJSON(data []byte) error {
+	var raw eventRecordJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	newEvent, known := eventFactories[raw.Type]
+	if !known {
+		return fmt.Errorf("unknown event type %q", raw.Type)
+	}
+	event := newEvent()
+	if err := json.Unmarshal(raw.Data, event); err != nil {
+		return fmt.Errorf("invalid %s event: %w", raw.Type, err)
+	}
+	r.Sequence, r.Time, r.Event = raw.Sequence, raw.Time, event
+	return nil
+}
+
+// fold is the state rebuilt from the event log
+type fold struct {
+	state *types.GameState
+	// Placement cells chosen by the players before the fight
+	chosenPositions map[string]types.Position
+	fightStats      map[string]types.FightStats
+	fightStartedAt  time.Time
+	// Player who dealt the killing blow to each dead character
+	killers map[string]string
+}
+
+func newFold() *fold {
+	return &fold{
+		state: &types.GameState{
+			MessageType: "game_state",
+			Players:     make(map[string]types.Player),
+			GameStatus:  PhaseLobby,
+		},
+		chosenPositions: make(map[string]types.Position),
+		fightStats:      make(map[string]types.FightStats),
+		killers:         make(map[string]string),
+	}
+}
+
+// Fold rebuilds the game state from an event log
+func Fold(records []EventRecord) *types.GameState {
+	f := newFold()
+	for _, record := range records {
+		record.Event.apply(f)
+	}
+	return f.state
+}
+
+// character returns the character of a player, or nil
+func (f *fold) character(userID string) *types.Character {
+	return f.state.Players[userID].Character
+}
+
+// LobbyOpened starts a new game in the lobby, on the given map
+type LobbyOpened struct {
+	Map *types.BoardMap `json:"map"`
+}
+
+func (e *LobbyOpened) EventType() string { return "lobby_opened" }
+
+func (e *LobbyOpened) apply(f *fold) {
+	*f = *newFold()
+	f.state.Map = e.Map
+}
+
+// GameStarted closes the lobby with its players, their offered placement cells,
+// the spells and the seed of the game's random source
+type GameStarted struct {
+	Players map[string]types.Player `json:"players"`
+	Spells  map[string]types.Spell  `json:"spells"`
+	Seed    int64                   `json:"seed"`
+}
+
+func (e *GameStarted) EventType() string { return "game_started" }
+
+func (e *GameStarted) apply(f *fold) {
+	f.state.Players = make(map[string]types.Player, len(e.Players))
+	for userID, player := range e.Players {
+		player.Character = copyCharacter(player.Character)
+		f.state.Players[userID] = player
+	}
+	f.state.Spells = make(map[string]types.Spell, len(e.Spells))
+	for spellID, spell := range e.Spells {
+		f.state.Spells[spellID] = spell
+	}
+	f.state.GameStatus = PhasePlacement
+	f.state.TurnNumber = 0
+}
+
+// InitialPositionChosen records the placement cell chosen by a player
+type InitialPositionChosen struct {
+	UserID   string         `json:"userId"`
+	Position types.Position `json:"position"`
+}
+
+func (e *InitialPositionChosen) EventType() string { return "initial_position_chosen" }
+
+func (e *InitialPositionChosen) apply(f *fold) {
+	f.chosenPositions[e.UserID] = e.Position
+	if player, exists := f.state.Players[e.UserID]; exists {
+		player.HasPositioned = true
+		f.state.Players[e.UserID] = player
+	}
+}
+
+// CharactersPlaced puts the characters on their chosen cells
+type CharactersPlaced struct {
+	Positions map[string]types.Position `json:"positions"`
+}
+
+func (e *CharactersPlaced) EventType() string { return "characters_placed" }
+
+func (e *CharactersPlaced) apply(f *fold) {
+	for userID, position := range e.Positions {
+		if character := f.character(userID); character != nil {
+			position := position
+			character.Position = &position
+		}
+	}
+	f.chosenPositions = make(map[string]types.Position)
+}
+
+// PhaseChanged moves the game to another phase
+type PhaseChanged struct {
+	Phase string `json:"phase"`
+}
+
+func (e *PhaseChanged) EventType() string { return "phase_changed" }
+
+func (e *PhaseChanged) apply(f *fold) {
+	f.state.GameStatus = e.Phase
+}
+
+// FightStarted sets the turn order of the fight
+type FightStarted struct {
+	Timeline  []string  `json:"timeline"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+func (e *FightStarted) EventType() string { return "fight_started" }
+
+func (e *FightStarted) apply(f *fold) {
+	f.state.Timeline = append([]string(nil), e.Timeline...)
+	f.state.CurrentTurnIndex = 0
+	f.state.TurnNumber = 1
+	f.fightStats = make(map[string]types.FightStats)
+	f.killers = make(map[string]string)
+	f.fightStartedAt = e.StartedAt
+}
+
+// TurnStarted gives the turn to a player: its character gets its AP and MP back
+// and its spell cooldowns count down
+type TurnStarted struct {
+	UserID     string `json:"userId"`
+	TurnIndex  int    `json:"turnIndex"`
+	TurnNumber int    `json:"turnNumber"`
+}
+
+func (e *TurnStarted) EventType() string { return "turn_started" }
+
+func (e *TurnStarted) apply(f *fold) {
+	newRound := e.TurnNumber != f.state.TurnNumber
+	f.state.CurrentTurnIndex = e.TurnIndex
+	f.state.TurnNumber = e.TurnNumber
+
+	for userID, player := range f.state.Players {
+		player.IsCurrentTurn = userID == e.UserID
+		if player.Character != nil {
+			player.Character.IsCurrentTurn = player.IsCurrentTurn
+			if newRound {
+				player.Character.HasPlayedThisTurn = false
+			}
+		}
+		f.state.Players[userID] = player
+	}
+
+	character := f.character(e.UserID)
+	if character == nil {
+		return
+	}
+	character.ActionPoints = DefaultActionPoints
+	character.MovementPoints = DefaultMovementPoints
+	for spellID, remaining := range character.SpellCooldowns {
+		if remaining <= 1 {
+			delete(character.SpellCooldowns, spellID)
+		} else {
+			character.SpellCooldowns[spellID] = remaining - 1
+		}
+	}
+}
+
+// CharacterMoved walks a character along a path, paying one MP per cell
+type CharacterMoved struct {
+	UserID string           `json:"userId"`
+	Path   []types.Position `json:"path"`
+}
+
+func (e *CharacterMoved) EventType() string { return "character_moved" }
+
+func (e *CharacterMoved) apply(f *fold) {
+	character := f.character(e.UserID)
+	if character == nil || len(e.Path) == 0 {
+		return
+	}
+	destination := e.Path[len(e.Path)-1]
+	character.Position = &destination
+	character.MovementPoints -= len(e.Path)
+}
+
+// SpellCast charges a cast to its caster: AP cost, casts this turn and cooldown.
+// Its outcome follows as DamageApplied and EffectApplied events.
+type SpellCast struct {
+	CasterID string         `json:"casterId"`
+	SpellID  int            `json:"spellId"`
+	Target   types.Position `json:"target"`
+	Critical bool           `json:"critical"`
+	APCost   int            `json:"apCost"`
+	Cooldown int            `json:"cooldown,omitempty"`
+}
+
+func (e *SpellCast) EventType() string { return "spell_cast" }
+
+func (e *SpellCast) apply(f *fold) {
+	character := f.character(e.CasterID)
+	if character == nil {
+		return
+	}
+	spellID := strconv.Itoa(e.SpellID)
+
+	character.ActionPoints -= e.APCost
+	if character.SpellCastsThisTurn == nil {
+		character.SpellCastsThisTurn = make(map[string]int)
+	}
+	character.SpellCastsThisTurn[spellID]++
+	if e.Cooldown > 0 {
+		if character.SpellCooldowns == nil {
+			character.SpellCooldowns = make(map[string]int)
+		}
+		character.SpellCooldowns[spellID] = e.Cooldown
+	}
+}
+
+// DamageApplied deals damage to a character, from a spell or an effect of a player
+type DamageApplied struct {
+	SourceID string `json:"sourceId"`
+	TargetID string `json:"targetId"`
+	Amount   int    `json:"amount"`
+}
+
+func (e *DamageApplied) EventType() string { return "damage_applied" }
+
+func (e *DamageApplied) apply(f *fold) {
+	character := f.character(e.TargetID)
+	if character == nil {
+		return
+	}
+
+	// Damage beyond the remaining health of the target is not counted
+	dealt := min(e.Amount, max(0, character.Health))
+	character.Health -= e.Amount
+	killed := character.Health <= 0 && character.IsAlive
+	if killed {
+		character.IsAlive = false
+		f.killers[e.TargetID] = e.SourceID
+	}
+
+	source := f.fightStats[e.SourceID]
+	source.DamageDealt += dealt
+	if killed {
+		source.Kills++
+	}
+	f.fightStats[e.SourceID] = source
+
+	target := f.fightStats[e.TargetID]
+	target.DamageTaken += dealt
+	f.fightStats[e.TargetID] = target
+}
+
+// EffectApplied attaches a status effect to a character
+type EffectApplied struct {
+	TargetID string             `json:"targetId"`
+	Effect   types.StatusEffect `json:"effect"`
+}
+
+func (e *EffectApplied) EventType() string { return "effect_applied" }
+
+func (e *EffectApplied) apply(f *fold) {
+	if character := f.character(e.TargetID); character != nil {
+		addStatusEffect(character, e.Effect)
+	}
+}
+
+// PointsChanged adds AP and MP to a character, or removes them when negative
+type PointsChanged struct {
+	UserID         string `json:"userId"`
+	ActionPoints   int    `json:"actionPoints"`
+	MovementPoints int    `json:"movementPoints"`
+}
+
+func (e *PointsChanged) EventType() string { return "points_changed" }
+
+func (e *PointsChanged) apply(f *fold) {
+	if character := f.character(e.UserID); character != nil {
+		character.ActionPoints = max(0, character.ActionPoints+e.ActionPoints)
+		character.MovementPoints = max(0, character.MovementPoints+e.MovementPoints)
+	}
+}
+
+// TurnEnded ends the turn of a player: its casts per turn are reset and its
+// status effects count down
+type TurnEnded struct {
+	UserID string `json:"userId"`
+}
+
+func (e *TurnEnded) EventType() string { return "turn_ended" }
+
+func (e *TurnEnded) apply(f *fold) {
+	character := f.character(e.UserID)
+	if character == nil {
+		return
+	}
+	character.HasPlayedThisTurn = true
+	character.SpellCastsThisTurn = nil
+	countEffectsDown(character)
+}
+
+// copyCharacter deep copies a character, so that the fold never shares memory with an event
+func copyCharacter(character *types.Character) *types.Character {
+	if character == nil {
+		return nil
+	}
+	c := *character
+	if character.Position != nil {
+		position := *character.Position
+		c.Position = &position
+	}
+	c.InitialPositions = make([]*types.Position, 0, len(character.InitialPositions))
+	for _, initialPosition := range character.InitialPositions {
+		if initialPosition != nil {
+			position := *initialPosition
+			c.InitialPositions = append(c.InitialPositions, &position)
+		}
+	}
+	c.SpellCooldowns = copyCounts(character.SpellCooldowns)
+	c.SpellCastsThisTurn = copyCounts(character.SpellCastsThisTurn)
+	c.Effects = append([]types.StatusEffect(nil), character.Effects...)
+	return &c
+}
+
+func copyCounts(counts map[string]int) map[string]int {
+	if counts == nil {
+		return nil
+	}
+	c := make(map[string]int, len(counts))
+	for k, v := range counts {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,33 @@
+package game
+
+import (
+	"game-server/internal/types"
+	"time"
+)
+
+// FightStats returns the damage and kills of each player in the current fight
+func (gm *GameManager) FightStats() map[string]types.FightStats {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	stats := make(map[string]types.FightStats, len(gm.fold.fightStats))
+	for userID, playerStats := range gm.fold.fightStats {
+		stats[userID] = playerStats
+	}
+	return stats
+}
+
+// FightStartedAt returns when the current fight started
+func (gm *GameManager) FightStartedAt() time.Time {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+	return gm.fold.fightStartedAt
+}
+
+// Killer returns the player who dealt the killing blow to a dead character
+func (gm *GameManager) Killer(userID string) (string, bool) {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+	killerID, killed := gm.fold.killers[userID]
+	return killerID, killed
+}
@@ -3,75 +3,59 @@ package game
 
 import (
 	"encoding/json"
-	"errors"
 	"game-server/internal/types"
 	"log"
 	"math/rand"
+	"sort"
 	"sync"
+	"time"
 )
 
-// Game status constants
-const (
-	GameStatusHasNotStarted      = "creating_player"
-	GameStatusWaiting            = "waiting"
-	GameStatusInProgress         = "in_progress"
-	GameStatusGameOver           = "game_over"
-	GameStatusPositionCharacters = "position_characters"
+var (
+	ErrNotEnoughPlayers = &RuleError{Code: types.ReasonNotEnoughPlayers, Message: "not enough players to start game"}
+	ErrPlayersNotReady  = &RuleError{Code: types.ReasonPlayersNotReady, Message: "not all players are ready"}
+	ErrSingleTeam       = &RuleError{Code: types.ReasonSingleTeam, Message: "all players are in the same team"}
 )
 
 type GameManager struct {
-	state                  []*types.GameState
-	messageHistory         [][]byte // Add this field to store message history
-	chosenInitialPositions map[string]types.Position
-	mutex                  sync.RWMutex
+	// Append-only log of the game's events; the current state is their fold
+	events       []EventRecord
+	fold         *fold
+	spells       *SpellCatalogue
+	board        *Board
+	friendlyFire bool
+	// Random source of the current game, seeded when the game starts
+	rng   *rand.Rand
+	mutex sync.RWMutex
 }
 
-func NewGameManager() *GameManager {
-	return &GameManager{
-		state: []*types.GameState{{
-			MessageType: "game_state",
-			Players:     make(map[string]types.Player),
-			GameStatus:  GameStatusHasNotStarted,
-			TurnNumber:  0,
-		}},
-		messageHistory:         make([][]byte, 0), // Initialize message history
-		chosenInitialPositions: make(map[string]types.Position),
+func NewGameManager(spells *SpellCatalogue, board *Board) *GameManager {
+	gm := &GameManager{
+		fold:         newFold(),
+		spells:       spells,
+		board:        board,
+		friendlyFire: true,
 	}
+	gm.record(&LobbyOpened{Map: board.Layout()})
+	return gm
 }
 
-// AddToHistory adds a message to the game history
-func (gm *GameManager) AddToHistory(message []byte) error {
-	gm.mutex.Lock()
-	defer gm.mutex.Unlock()
-
-	// Create a copy of the message to prevent external modifications
-	messageCopy := make([]byte, len(message))
-	copy(messageCopy, message)
-
-	gm.messageHistory = append(gm.messageHistory, messageCopy)
-	return nil
-}
-
-// GetMessageHistory returns a copy of the message history
-func (gm *GameManager) GetMessageHistory() [][]byte {
-	gm.mutex.RLock()
-	defer gm.mutex.RUnlock()
-
-	// Create a deep copy of the message history
-	historyCopy := make([][]byte, len(gm.messageHistory))
-	for i, msg := range gm.messageHistory {
-		historyCopy[i] = make([]byte, len(msg))
-		copy(historyCopy[i], msg)
-	}
-
-	return historyCopy
+// record appends an event to the log and applies it to the current state.
+// The caller must hold the mutex.
+func (gm *GameManager) record(event Event) {
+	gm.events = append(gm.events, EventRecord{
+		Sequence: len(gm.events) + 1,
+		Time:     time.Now(),
+		Event:    event,
+	})
+	event.apply(gm.fold)
 }
 
-// GetCurrentState returns the current game state
+// GetCurrentState returns the current game state. It must not be modified.
 func (gm *GameManager) GetCurrentState() *types.GameState {
 	gm.mutex.RLock()
 	defer gm.mutex.RUnlock()
-	return gm.state[len(gm.state)-1]
+	return gm.fold.state
 }
 
 // GetStatus returns the current game status
@@ -79,263 +63,370 @@ func (gm *GameManager) GetStatus() string {
 	return gm.GetCurrentState().GameStatus
 }
 
+// HasCharacter reports whether a player has a character in the game, dead or alive
+func (gm *GameManager) HasCharacter(userID string) bool {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+	player, exists := gm.fold.state.Players[userID]
+	return exists && player.Character != nil
+}
+
 // GetTurnNumber returns the current turn number
 func (gm *GameManager) GetTurnNumber() int {
 	return gm.GetCurrentState().TurnNumber
 }
 
-// func to increment turn number
-func (gm *GameManager) IncrementTurnNumber() {
-	gm.mutex.Lock()
-	defer gm.mutex.Unlock()
-
-	currentState := gm.state[len(gm.state)-1]
-	currentState.TurnNumber = currentState.TurnNumber + 1
-	gm.state = append(gm.state, currentState)
-}
-
-// Return the first character with HasPlayedThisTurn = false
-func (gm *GameManager) GetNextCharacter() (*types.Character, error) {
+// CheckGameOver checks if the game has ended, once at most one team has living
+// characters, and returns the winning team if so. The winning team is empty when
+// the last characters died together.
+func (gm *GameManager) CheckGameOver() (string, bool) {
 	gm.mutex.RLock()
 	defer gm.mutex.RUnlock()
 
-	currentState := gm.state[len(gm.state)-1]
+	currentState := gm.fold.state
 
+	aliveTeams := make(map[string]bool)
 	for _, player := range currentState.Players {
-		if !player.Character.HasPlayedThisTurn {
-			return player.Character, nil
+		if player.Character != nil && player.Character.IsAlive {
+			aliveTeams[player.Team] = true
 		}
 	}
 
-	return nil, errors.New("no characters available for next turn")
-}
-
-// CheckGameOver checks if the game has ended and returns the winner's ID if so.
-func (gm *GameManager) CheckGameOver() (string, bool) {
-	gm.mutex.RLock()
-	defer gm.mutex.RUnlock()
-
-	currentState := gm.state[len(gm.state)-1]
-
-	alivePlayers := []string{}
-	for userID, player := range currentState.Players {
-		if player.Character.IsAlive {
-			alivePlayers = append(alivePlayers, userID)
+	switch len(aliveTeams) {
+	case 0:
+		return "", true // Game over, nobody is left
+	case 1:
+		for team := range aliveTeams {
+			return team, true // Game over, return the winning team
 		}
 	}
 
-	if len(alivePlayers) == 1 {
-		return alivePlayers[0], true // Game over, return the winner's ID
-	}
-
 	return "", false // Game not over
 }
 
-func (gm *GameManager) SetHasPlayedThisTurn(userID string, HasPlayedThisTurn bool) error {
+// EndTurn ends a player's turn: its casts per turn are reset and its status
+// effects count down, dropping the expired ones
+func (gm *GameManager) EndTurn(userID string) error {
 	gm.mutex.Lock()
 	defer gm.mutex.Unlock()
 
-	currentState := gm.state[len(gm.state)-1]
-
-	// Check if player exists
-	player, exists := currentState.Players[userID]
-	if !exists {
-		return errors.New("player not found")
-	}
-
-	// Create new state with updated hasPlayedThisTurn
-	newState := &types.GameState{
-		MessageType: "game_state",
-		Players:     make(map[string]types.Player),
-		GameStatus:  currentState.GameStatus,
-		TurnNumber:  currentState.TurnNumber,
-		Spells:      currentState.Spells,
-	}
-
-	// Update hasPlayedThisTurn for the player
-	player.Character.HasPlayedThisTurn = HasPlayedThisTurn
-
-	// Copy players to new state
-	for k, v := range currentState.Players {
-		if k == userID {
-			v = player // Update the specific player
-		}
-		newState.Players[k] = v
+	player, exists := gm.fold.state.Players[userID]
+	if !exists || player.Character == nil {
+		return ErrPlayerNotFound
 	}
-
-	gm.state = append(gm.state, newState)
+	gm.record(&TurnEnded{UserID: userID})
 	return nil
 }
 
-// Given a character name, set it's IsCurrentTurn field
-func (gm *GameManager) SetCharacterCurrentTurn(CharacterName string, isCurrent bool) error {
+// SetGameStatus moves the game to another phase, if the phase state machine allows it
+func (gm *GameManager) SetGameStatus(status string) error {
 	gm.mutex.Lock()
 	defer gm.mutex.Unlock()
 
-	currentState := gm.state[len(gm.state)-1]
-	// Create new state with updated isCurrentTurn
-	newState := &types.GameState{
-		MessageType: "game_state",
-		Players:     make(map[string]types.Player),
-		GameStatus:  currentState.GameStatus,
-		TurnNumber:  currentState.TurnNumber,
-		Spells:      currentState.Spells,
-	}
-	// Copy players to new state, updating isCurrentTurn for the specified character
-	for k, v := range currentState.Players {
-		if v.Character.Name == CharacterName {
-			v.Character.IsCurrentTurn = isCurrent
-		}
-		newState.Players[k] = v
+	if err := checkTransition(gm.fold.state.GameStatus, status); err != nil {
+		return err
 	}
-	gm.state = append(gm.state, newState)
+	gm.record(&PhaseChanged{Phase: status})
 	return nil
 }
 
-func (gm *GameManager) SetGameStatus(status string) {
+// ResetToLobby starts a new game in the lobby once the previous one is finished
+func (gm *GameManager) ResetToLobby() error {
 	gm.mutex.Lock()
 	defer gm.mutex.Unlock()
 
-	currentState := gm.state[len(gm.state)-1]
-	currentState.GameStatus = status
-	gm.state = append(gm.state, currentState)
+	if err := checkTransition(gm.fold.state.GameStatus, PhaseLobby); err != nil {
+		return err
+	}
+	gm.events = nil
+	gm.record(&LobbyOpened{Map: gm.board.Layout()})
+	return nil
 }
 
-func (gm *GameManager) SetChosenInitialPosition(userID string, position types.Position) {
+// SetChosenInitialPosition records the placement cell chosen by a player. The cell
+// must be one of the initial positions offered to its character and not already
+// chosen by another player.
+func (gm *GameManager) SetChosenInitialPosition(userID string, position types.Position) error {
 	gm.mutex.Lock()
 	defer gm.mutex.Unlock()
-	gm.chosenInitialPositions[userID] = position
+
+	player, exists := gm.fold.state.Players[userID]
+	if !exists || player.Character == nil {
+		return ErrPlayerNotFound
+	}
+
+	offered := false
+	for _, initialPosition := range player.Character.InitialPositions {
+		if initialPosition != nil && *initialPosition == position {
+			offered = true
+			break
+		}
+	}
+	if !offered {
+		return ErrInvalidPlacement
+	}
+
+	for otherUserID, chosen := range gm.fold.chosenPositions {
+		if otherUserID != userID && chosen == position {
+			return ErrCellOccupied
+		}
+	}
+
+	gm.record(&InitialPositionChosen{UserID: userID, Position: position})
+	return nil
 }
 
 func (gm *GameManager) AreAllPlayersPositioned(totalPlayers int) bool {
 	gm.mutex.RLock()
 	defer gm.mutex.RUnlock()
-	return len(gm.chosenInitialPositions) == totalPlayers
+	return len(gm.fold.chosenPositions) == totalPlayers
 }
 
 func (gm *GameManager) ApplyAllChosenPositions() error {
 	gm.mutex.Lock()
 	defer gm.mutex.Unlock()
 
-	currentState := gm.state[len(gm.state)-1]
-	newState := &types.GameState{
-		MessageType: "game_state",
-		Players:     make(map[string]types.Player),
-		GameStatus:  currentState.GameStatus,
-		TurnNumber:  currentState.TurnNumber,
-		Spells:      currentState.Spells,
-	}
-
-	for userID, player := range currentState.Players {
-		if chosenPos, ok := gm.chosenInitialPositions[userID]; ok {
-			player.Character.Position = &chosenPos
+	positions := make(map[string]types.Position, len(gm.fold.chosenPositions))
+	for userID := range gm.fold.state.Players {
+		if chosenPos, ok := gm.fold.chosenPositions[userID]; ok {
+			positions[userID] = chosenPos
 		} else {
 			// If a player hasn't chosen a position, they might be a spectator or an error occurred
 			log.Printf("[Warning] Player %s did not choose an initial position.", userID)
 		}
-		newState.Players[userID] = player
 	}
 
-	gm.state = append(gm.state, newState)
-	gm.chosenInitialPositions = make(map[string]types.Position) // Clear chosen positions
+	gm.record(&CharactersPlaced{Positions: positions})
 	return nil
 }
 
-func (gm *GameManager) UpdatePlayerPosition(playerID string, newPosition types.Position) error {
+// MoveCharacter walks a player's character along a path found by FindMovePath
+func (gm *GameManager) MoveCharacter(playerID string, path []types.Position) error {
 	gm.mutex.Lock()
 	defer gm.mutex.Unlock()
 
-	currentState := gm.state[len(gm.state)-1]
-
-	// Create new state with updated position
-	newState := &types.GameState{
-		MessageType: "game_state",
-		Players:     make(map[string]types.Player),
-		GameStatus:  currentState.GameStatus,
-		TurnNumber:  currentState.TurnNumber,
-		Spells:      currentState.Spells,
-	}
-
-	//Copy players and update position
-	for k, v := range currentState.Players {
-		if k == playerID {
-			v.Character.Position = &newPosition
-		}
-		newState.Players[k] = v
+	if _, err := gm.fighter(playerID); err != nil {
+		return err
 	}
+	log.Printf("[Game] Distance moved by player %s: %d", playerID, len(path))
 
-	gm.state = append(gm.state, newState)
+	gm.record(&CharacterMoved{UserID: playerID, Path: append([]types.Position(nil), path...)})
 	return nil
 }
 
-func (gm *GameManager) ApplySpellDamages(spellID string, affectedPositions []types.Position) error {
+// CastSpell casts a spell from a player's character on the target cell, once the cast
+// has been checked. It charges the AP cost and the spell usage, deals the spell's damage
+// to every living character in the affected positions and attaches the spell's status
+// effects to the survivors. The critical hit is rolled once per cast with the game's
+// seeded random source. It returns the damage dealt to each character and whether the
+// cast was critical.
+func (gm *GameManager) CastSpell(casterID string, spellID string, target types.Position) ([]types.SpellHit, bool, error) {
 	gm.mutex.Lock()
 	defer gm.mutex.Unlock()
 
-	currentState := gm.state[len(gm.state)-1]
+	currentState := gm.fold.state
 
 	// Find the spell in the spell list
 	spell, exists := currentState.Spells[spellID]
 	if !exists {
-		return errors.New("spell not found")
+		return nil, false, ErrUnknownSpell
+	}
+	caster, err := gm.fighter(casterID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	critical := gm.rollCritical(spell)
+	damage := spell.Damage
+	if critical && spell.CriticalDamage > 0 {
+		damage = spell.CriticalDamage
 	}
 
+	gm.record(&SpellCast{
+		CasterID: casterID,
+		SpellID:  spell.ID,
+		Target:   target,
+		Critical: critical,
+		APCost:   spell.APCost,
+		Cooldown: spell.Cooldown,
+	})
+
 	// Apply damage to all players in the affected positions
-	for _, position := range affectedPositions {
+	hits := []types.SpellHit{}
+	for _, position := range affectedPositions(spell, target, *caster.Character.Position) {
 		log.Printf("[Debug] Checking position: %+v", position)
-		for userID, v := range currentState.Players {
-			if v.Character.Position != nil && v.Character.Position.X == position.X && v.Character.Position.Y == position.Y {
-				log.Printf("[Debug] Applying %d damage to player %s at position %+v (current health: %d)", spell.Damage, userID, *v.Character.Position, v.Character.Health)
-				v.Character.Health -= spell.Damage
-				if v.Character.Health <= 0 {
-					v.Character.IsAlive = false
-					log.Printf("[Debug] Player %s is now dead.", userID)
+		for _, userID := range gm.charactersHit(caster, position) {
+			v := currentState.Players[userID]
+			dealt := damageTaken(v.Character, damage)
+			log.Printf("[Debug] Applying %d damage (critical: %t) to player %s at position %+v (current health: %d)", dealt, critical, userID, *v.Character.Position, v.Character.Health)
+			gm.record(&DamageApplied{SourceID: casterID, TargetID: userID, Amount: dealt})
+			if !v.Character.IsAlive {
+				log.Printf("[Debug] Player %s is now dead.", userID)
+			} else {
+				for _, effect := range spell.Effects {
+					gm.record(&EffectApplied{
+						TargetID: userID,
+						Effect: types.StatusEffect{
+							Kind:           effect.Kind,
+							Value:          effect.Value,
+							RemainingTurns: effect.Duration,
+							StackPolicy:    effect.StackPolicy,
+							SourceUserID:   casterID,
+							SpellID:        spell.ID,
+						},
+					})
 				}
-				// Update the player in the current state
-				currentState.Players[userID] = v
 			}
+
+			hits = append(hits, types.SpellHit{
+				UserID:   userID,
+				Position: position,
+				Damage:   dealt,
+				Critical: critical,
+				IsDead:   !v.Character.IsAlive,
+			})
 		}
 	}
 
-	gm.state = append(gm.state, currentState)
-	return nil
+	return hits, critical, nil
 }
 
-func (gm *GameManager) GetSpellCost(spellID string) (int, error) {
-	// This function should return the cost of the spell based on its ID
-	// For now, we will return a fixed cost for demonstration purposes
-	switch spellID {
-	case "1":
-		return 4, nil
-	case "2":
-		return 3, nil
-	case "3":
-		return 2, nil
-	case "4":
-		return 5, nil
-	case "5":
-		return 0, nil // Kill spell costs 0 AP
-	default:
-		return 0, errors.New("unknown spell ID")
-	}
-}
-
-func (gm *GameManager) GetAffectedPositions(spellID string, targetPosition types.Position, casterPosition types.Position) ([]types.Position, error) {
-	currentState := gm.GetCurrentState()
+// charactersHit returns the living characters a spell cast by caster hits on a
+// cell, ordered by user ID. Without friendly fire, the caster's team is spared.
+// The caller must hold the mutex.
+func (gm *GameManager) charactersHit(caster types.Player, position types.Position) []string {
+	var userIDs []string
+	for userID, player := range gm.fold.state.Players {
+		if !gm.friendlyFire && player.Team == caster.Team {
+			continue
+		}
+		if player.Character != nil && player.Character.IsAlive && player.Character.Position != nil && *player.Character.Position == position {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Strings(userIDs)
+	return userIDs
+}
+
+// rollCritical draws whether a cast of the spell is a critical hit.
+// The caller must hold the mutex.
+func (gm *GameManager) rollCritical(spell types.Spell) bool {
+	if spell.CriticalChance <= 0 {
+		return false
+	}
+	return gm.rng.Intn(100) < spell.CriticalChance
+}
+
+// ValidateSpellTarget checks that a player's character can cast a spell on the target cell.
+// Obstacles and living characters other than the caster and the target block the line of sight.
+func (gm *GameManager) ValidateSpellTarget(playerID string, spellID string, target types.Position) error {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	currentState := gm.fold.state
+
 	spell, exists := currentState.Spells[spellID]
 	if !exists {
-		return nil, errors.New("spell not found")
+		return ErrUnknownSpell
 	}
 
-	var affectedPositions []types.Position
-	pattern := []types.Position{}
-	rotatePattern := false
-
-	switch spell.AreaOfEffect {
-	case "none":
-		pattern = append(pattern, types.Position{X: 0, Y: 0})
-	case "circle":
-		pattern = append(pattern, []types.Position{
+	player, err := gm.fighter(playerID)
+	if err != nil {
+		return err
+	}
+
+	return gm.checkSpellTargetFrom(playerID, spell, *player.Character.Position, target)
+}
+
+// SpellArea returns the cells of the board a player's spell would hit if cast on the target cell
+func (gm *GameManager) SpellArea(playerID string, spellID string, target types.Position) ([]types.Position, error) {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	caster, spell, err := gm.casterSpell(playerID, spellID)
+	if err != nil {
+		return nil, err
+	}
+	return gm.spellArea(spell, *caster.Character.Position, target), nil
+}
+
+// casterSpell returns a player about to cast a spell and the spell, checking that
+// its character can act. The caller must hold the mutex.
+func (gm *GameManager) casterSpell(playerID string, spellID string) (types.Player, types.Spell, error) {
+	spell, exists := gm.fold.state.Spells[spellID]
+	if !exists {
+		return types.Player{}, types.Spell{}, ErrUnknownSpell
+	}
+	player, err := gm.fighter(playerID)
+	if err != nil {
+		return types.Player{}, types.Spell{}, err
+	}
+	return player, spell, nil
+}
+
+// fighter returns a player whose character can act in the fight: alive and
+// standing on the board. The caller must hold the mutex.
+func (gm *GameManager) fighter(playerID string) (types.Player, error) {
+	player, exists := gm.fold.state.Players[playerID]
+	if !exists || player.Character == nil {
+		return types.Player{}, ErrPlayerNotFound
+	}
+	if !player.Character.IsAlive {
+		return types.Player{}, ErrCharacterDead
+	}
+	if player.Character.Position == nil {
+		return types.Player{}, ErrNoPosition
+	}
+	return player, nil
+}
+
+// spellArea returns the cells of the board hit by a spell cast from from on target
+func (gm *GameManager) spellArea(spell types.Spell, from, target types.Position) []types.Position {
+	var area []types.Position
+	for _, position := range affectedPositions(spell, target, from) {
+		if gm.board.Contains(position) {
+			area = append(area, position)
+		}
+	}
+	return area
+}
+
+// checkSpellTargetFrom checks a cast of a player's spell as if its character stood on from.
+// The caller must hold the mutex.
+func (gm *GameManager) checkSpellTargetFrom(playerID string, spell types.Spell, from, target types.Position) error {
+	occupied := gm.occupiedCells(playerID)
+	return CheckSpellTarget(spell, gm.board, from, target,
+		func(pos types.Position) bool {
+			return occupied[pos] || pos == from
+		},
+		func(pos types.Position) bool {
+			return occupied[pos] || gm.board.BlocksLineOfSight(pos)
+		},
+	)
+}
+
+// GetSpellCost returns the AP cost of a spell from the spell catalogue
+func (gm *GameManager) GetSpellCost(spellID string) (int, error) {
+	spell, exists := gm.spells.Get(spellID)
+	if !exists {
+		return 0, ErrUnknownSpell
+	}
+	return spell.APCost, nil
+}
+
+// areaOfEffectPattern lists the cells hit by a spell relative to its target cell.
+// Rotated patterns are written for a cast going "up" and turned to face the caster's direction.
+type areaOfEffectPattern struct {
+	offsets []types.Position
+	rotate  bool
+}
+
+var areaOfEffectPatterns = map[string]areaOfEffectPattern{
+	"none": {
+		offsets: []types.Position{{X: 0, Y: 0}},
+	},
+	"circle": {
+		offsets: []types.Position{
 			{X: 2, Y: 0},
 			{X: 1, Y: 1},
 			{X: 0, Y: 2},
@@ -344,36 +435,41 @@ func (gm *GameManager) GetAffectedPositions(spellID string, targetPosition types
 			{X: 1, Y: -1},
 			{X: 0, Y: -2},
 			{X: -1, Y: -1},
-		}...
-		)
-	case "line":
-		pattern = append(pattern, []types.Position{
+		},
+	},
+	"line": {
+		offsets: []types.Position{
 			{X: 0, Y: 0},
 			{X: 0, Y: 1},
 			{X: 0, Y: 2},
-		}...
-		)
-		rotatePattern = true
-	case "cross":
-		pattern = append(pattern, []types.Position{
+		},
+		rotate: true,
+	},
+	"cross": {
+		offsets: []types.Position{
 			{X: 0, Y: 0},
 			{X: 0, Y: 1},
 			{X: 1, Y: 0},
 			{X: -1, Y: 0},
 			{X: 0, Y: -1},
-		}...
-		)
-		rotatePattern = true
-	}
+		},
+		rotate: true,
+	},
+}
+
+// affectedPositions returns the cells hit by a spell cast from casterPosition on targetPosition
+func affectedPositions(spell types.Spell, targetPosition types.Position, casterPosition types.Position) []types.Position {
+	var affectedPositions []types.Position
+	pattern := areaOfEffectPatterns[spell.AreaOfEffect]
 
 	direction := ""
-	if rotatePattern {
+	if pattern.rotate {
 		direction = getDirection(casterPosition, targetPosition)
 	}
 
-	for _, offset := range pattern {
+	for _, offset := range pattern.offsets {
 		transformed := offset
-		if rotatePattern {
+		if pattern.rotate {
 			transformed = rotate(offset, direction)
 		}
 		affectedPositions = append(affectedPositions, types.Position{
@@ -382,144 +478,132 @@ func (gm *GameManager) GetAffectedPositions(spellID string, targetPosition types
 		})
 	}
 
-	return affectedPositions, nil
+	return affectedPositions
 }
-func (gm *GameManager) UpdatePlayerAP(playerID string, newActionPoints int) error {
-	gm.mutex.Lock()
-	defer gm.mutex.Unlock()
-
-	currentState := gm.state[len(gm.state)-1]
 
-	// Create new state with updated action points
-	newState := &types.GameState{
-		MessageType: "game_state",
-		Players:     make(map[string]types.Player),
-		GameStatus:  currentState.GameStatus,
-		TurnNumber:  currentState.TurnNumber,
-		Spells:      currentState.Spells,
-	}
+// FindMovePath returns the path a player's character would walk to reach target,
+// excluding its current cell. The move is rejected if the target is off the board,
+// not walkable or occupied, if obstacles, holes and other characters block every path,
+// or if the path is longer
+// than the character's remaining movement points.
+func (gm *GameManager) FindMovePath(playerID string, target types.Position) ([]types.Position, error) {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
 
-	// Copy players and update action points
-	for k, v := range currentState.Players {
-		if k == playerID {
-			v.Character.ActionPoints = v.Character.ActionPoints - newActionPoints
-		}
-		newState.Players[k] = v
+	player, err := gm.fighter(playerID)
+	if err != nil {
+		return nil, err
 	}
 
-	gm.state = append(gm.state, newState)
-	return nil
-}
-
-func (gm *GameManager) UpdatePlayerPM(playerID string, newPosition types.Position) error {
-	gm.mutex.Lock()
-	defer gm.mutex.Unlock()
-
-	currentState := gm.state[len(gm.state)-1]
-
-	originalPos := currentState.Players[playerID].Character.Position
-
-	// Create new state with updated position points
-	newState := &types.GameState{
-		MessageType: "game_state",
-		Players:     make(map[string]types.Player),
-		GameStatus:  currentState.GameStatus,
-		TurnNumber:  currentState.TurnNumber,
-		Spells:      currentState.Spells,
+	occupied := gm.occupiedCells(playerID)
+	path, err := FindPath(gm.board, *player.Character.Position, target, func(pos types.Position) bool {
+		return occupied[pos]
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Calculate distance moved
-	distance := func(p1, p2 *types.Position) int {
-		dx := p2.X - p1.X
-		dy := p2.Y - p1.Y
-		return abs(dx) + abs(dy)
+	if len(path) > player.Character.MovementPoints {
+		return nil, ErrNotEnoughMP
 	}
-	log.Printf("[Game] Distance moved by player %s: %d", playerID, distance(originalPos, &newPosition))
+	return path, nil
+}
 
-	// Copy players and update movement points directly on v before assigning to newState.Players[k]
-	for k, v := range currentState.Players {
-		if k == playerID {
-			v.Character.MovementPoints -= distance(originalPos, &newPosition)
+// occupiedCells returns the cells held by living characters, ignoring the given player.
+// The caller must hold the mutex.
+func (gm *GameManager) occupiedCells(excludedPlayerID string) map[types.Position]bool {
+	currentState := gm.fold.state
 
+	occupied := make(map[types.Position]bool)
+	for userID, player := range currentState.Players {
+		if userID == excludedPlayerID || player.Character == nil || player.Character.Position == nil {
+			continue
 		}
-		newState.Players[k] = v
+		if !player.Character.IsAlive {
+			continue
+		}
+		occupied[*player.Character.Position] = true
 	}
-
-	gm.state = append(gm.state, newState)
-	return nil
+	return occupied
 }
 
+// StartGame moves the lobby's players to the placement phase, once at least two
+// teams of ready players can fight
 func (gm *GameManager) StartGame(players map[string]types.Player) error {
 	gm.mutex.Lock()
 	defer gm.mutex.Unlock()
 
+	if err := checkTransition(gm.fold.state.GameStatus, PhasePlacement); err != nil {
+		return err
+	}
+
 	// Check if we have minimum number of players
 	if len(players) < 2 {
-		return errors.New("not enough players to start game")
+		return ErrNotEnoughPlayers
 	}
 
 	// Check if all players are ready
 	for _, player := range players {
 		if !player.IsReady {
-			return errors.New("not all players are ready")
+			return ErrPlayersNotReady
 		}
 	}
 
-	// For each character, create 3 random initial positions
-	for id, player := range players {
-		player.Character.InitialPositions = generateInitialPositions()
-		players[id] = player
-	}
-
-	// Update the game state
-	// Create new state with game started
-	newState := &types.GameState{
-		MessageType: "game_state",
-		Players:     players,
-		GameStatus:  GameStatusPositionCharacters,
-		TurnNumber:  0,
-		Spells:      initializeSpells(),
-	}
-
-	gm.state = append(gm.state, newState)
-	log.Printf("[Game] Game started with %d players", len(newState.Players))
+	// Check that at least two teams will fight
+	teams := make(map[string]bool)
+	for _, player := range players {
+		if !gm.IsTeam(player.Team) {
+			return ErrUnknownTeam
+		}
+		teams[player.Team] = true
+	}
+	if len(teams) < 2 {
+		return ErrSingleTeam
+	}
+
+	// Every game draws from its own seed, recorded so that a replay reproduces
+	// the placement cells, the turn order and the critical hits
+	seed := time.Now().UnixNano()
+	gm.rng = rand.New(rand.NewSource(seed))
+
+	// For each character, offer 3 random cells of its team's placement group.
+	// Players are visited in a fixed order so that the seed decides the positions.
+	// The characters are copied, so that the lobby keeps its own, and every fight
+	// starts with no spell on cooldown and no spell cast yet.
+	userIDs := make([]string, 0, len(players))
+	for id := range players {
+		userIDs = append(userIDs, id)
+	}
+	sort.Strings(userIDs)
+	offered := make(map[types.Position]bool)
+	startedPlayers := make(map[string]types.Player, len(players))
+	for _, id := range userIDs {
+		player := players[id]
+		player.Character = copyCharacter(player.Character)
+		player.Character.SpellCooldowns = nil
+		player.Character.SpellCastsThisTurn = nil
+		player.Character.InitialPositions = generateInitialPositions(gm.rng, gm.board.PlacementCells(player.Team), offered)
+		startedPlayers[id] = player
+	}
+
+	gm.record(&GameStarted{Players: startedPlayers, Spells: gm.spells.Spells(), Seed: seed})
+	log.Printf("[Game] Game started with %d players", len(startedPlayers))
 	return nil
 }
 
-func initializeSpells() map[string]types.Spell {
-	spells := make(map[string]types.Spell)
-	spells["1"] = types.Spell{ID: 1, Name: "Fireball", APCost: 4, Range: 6, Damage: 30, AreaOfEffect: "circle"}
-	spells["2"] = types.Spell{ID: 2, Name: "Ice Spike", APCost: 3, Range: 5, Damage: 20, AreaOfEffect: "line"}
-	spells["3"] = types.Spell{ID: 3, Name: "Poison Dart", APCost: 2, Range: 4, Damage: 10, AreaOfEffect: "none"}
-	spells["4"] = types.Spell{ID: 4, Name: "Gwendo na Gwendo", APCost: 5, Range: 3, Damage: 25, AreaOfEffect: "cross"}
-	spells["5"] = types.Spell{ID: 5, Name: "Kill", APCost: 0, Range: 0, Damage: 9999, AreaOfEffect: "none"}
-	return spells
-}
-
-// generateInitialPositions generates 3 random initial positions for a character
-func generateInitialPositions() []*types.Position {
-	// Create a hexagonal grid of positions in a circular pattern
-	// This generates all positions where abs(x) + abs(y) <= 7 and neither x nor y is 0
+// generateInitialPositions picks 3 random initial positions for a character among
+// the placement cells of its group. Cells already offered to another character are
+// only used once the group runs out of free cells.
+func generateInitialPositions(rng *rand.Rand, cells []types.Position, offered map[types.Position]bool) []*types.Position {
 	var allowedPositions []*types.Position
-
-	// Define the radius of our hexagonal grid
-	radius := 7
-
-	// Generate positions within the radius
-	for x := -radius; x <= radius; x++ {
-		for y := -radius; y <= radius; y++ {
-			// Skip positions where sum of absolute values exceeds radius
-			if abs(x)+abs(y) > radius {
-				continue
-			}
-
-			// Skip the center position (0,0) and any positions on the axes
-			if x == 0 || y == 0 {
-				continue
-			}
-
-			// Add position to our list
-			allowedPositions = append(allowedPositions, &types.Position{X: x, Y: y})
+	for _, cell := range cells {
+		if !offered[cell] {
+			allowedPositions = append(allowedPositions, &types.Position{X: cell.X, Y: cell.Y})
+		}
+	}
+	if len(allowedPositions) == 0 {
+		for _, cell := range cells {
+			allowedPositions = append(allowedPositions, &types.Position{X: cell.X, Y: cell.Y})
 		}
 	}
 
@@ -530,12 +614,15 @@ func generateInitialPositions() []*types.Position {
 	}
 
 	// Shuffle the positions
-	rand.Shuffle(len(allowedPositions), func(i, j int) {
+	rng.Shuffle(len(allowedPositions), func(i, j int) {
 		allowedPositions[i], allowedPositions[j] = allowedPositions[j], allowedPositions[i]
 	})
 
 	// Take the first numPositions elements
 	positions := allowedPositions[:numPositions]
+	for _, position := range positions {
+		offered[*position] = true
+	}
 
 	// Log the generated positions
 	jsonPositions, _ := json.Marshal(positions)
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"game-server/internal/game/gametest"
+	"game-server/internal/types"
+	"testing"
+)
+
+// newTestGameManager returns the lobby of a game on a small two-team board
+func newTestGameManager(t *testing.T) *GameManager {
+	t.Helper()
+	return newTestGameManagerWithSpells(t, gametest.Spells)
+}
+
+// newTestGameManagerWithSpells returns the lobby of a game on a small two-team
+// board, with the spells of a JSON catalogue
+func newTestGameManagerWithSpells(t *testing.T, catalogue string) *GameManager {
+	t.Helper()
+	spells, err := ParseSpellCatalogue([]byte(catalogue))
+	if err != nil {
+		t.Fatalf("failed to parse spells: %v", err)
+	}
+	board, err := NewBoard(gametest.BoardMap())
+	if err != nil {
+		t.Fatalf("failed to build board: %v", err)
+	}
+	return NewGameManager(spells, board)
+}
+
+// readyPlayers returns one ready player per team
+func readyPlayers() map[string]types.Player {
+	return map[string]types.Player{
+		"alice": {UserID: "alice", Team: "A", IsReady: true, Character: NewCharacter("Alice", "red", "A")},
+		"bob":   {UserID: "bob", Team: "B", IsReady: true, Character: NewCharacter("Bob", "blue", "B")},
+	}
+}
+
+func TestStartGameClearsSpellCounters(t *testing.T) {
+	gm := newTestGameManager(t)
+	players := readyPlayers()
+	players["alice"].Character.SpellCooldowns = map[string]int{"1": -5}
+	players["alice"].Character.SpellCastsThisTurn = map[string]int{"1": -5}
+
+	if err := gm.StartGame(players); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+
+	character := gm.GetCurrentState().Players["alice"].Character
+	if len(character.SpellCooldowns) != 0 || len(character.SpellCastsThisTurn) != 0 {
+		t.Errorf("spell counters = %v %v, want none", character.SpellCooldowns, character.SpellCastsThisTurn)
+	}
+	if players["alice"].Character.SpellCooldowns["1"] != -5 {
+		t.Error("the lobby character was modified")
+	}
+}
+
+// startTestFight starts the fight of the players, each on the first free cell offered to it
+func startTestFight(t *testing.T, gm *GameManager, players map[string]types.Player) {
+	t.Helper()
+	if err := gm.StartGame(players); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+	for userID := range gm.GetCurrentState().Players {
+		gametest.Place(t, gm, userID)
+	}
+	if err := gm.ApplyAllChosenPositions(); err != nil {
+		t.Fatalf("ApplyAllChosenPositions: %v", err)
+	}
+	if err := gm.SetGameStatus(PhaseFighting); err != nil {
+		t.Fatalf("SetGameStatus: %v", err)
+	}
+	if _, err := gm.StartFight(); err != nil {
+		t.Fatalf("StartFight: %v", err)
+	}
+}
+
+func TestDeadCharacterCannotAct(t *testing.T) {
+	gm := newTestGameManager(t)
+	startTestFight(t, gm, readyPlayers())
+
+	alice := *gm.GetCurrentState().Players["alice"].Character.Position
+	hits, _, err := gm.CastSpell("alice", "1", alice)
+	if err != nil {
+		t.Fatalf("CastSpell: %v", err)
+	}
+	if len(hits) != 1 || !hits[0].IsDead {
+		t.Fatalf("hits = %+v, want alice dead", hits)
+	}
+
+	bob := *gm.GetCurrentState().Players["bob"].Character.Position
+	if _, err := gm.FindMovePath("alice", types.Position{X: alice.X, Y: alice.Y + 1}); err != ErrCharacterDead {
+		t.Errorf("FindMovePath error = %v, want %v", err, ErrCharacterDead)
+	}
+	if err := gm.MoveCharacter("alice", []types.Position{{X: alice.X, Y: alice.Y + 1}}); err != ErrCharacterDead {
+		t.Errorf("MoveCharacter error = %v, want %v", err, ErrCharacterDead)
+	}
+	if _, _, err := gm.CastSpell("alice", "1", bob); err != ErrCharacterDead {
+		t.Errorf("CastSpell error = %v, want %v", err, ErrCharacterDead)
+	}
+	if err := gm.ValidateSpellTarget("alice", "1", bob); err != ErrCharacterDead {
+		t.Errorf("ValidateSpellTarget error = %v, want %v", err, ErrCharacterDead)
+	}
+	if _, err := gm.MoveRange("alice"); err != ErrCharacterDead {
+		t.Errorf("MoveRange error = %v, want %v", err, ErrCharacterDead)
+	}
+	if _, err := gm.SpellTargets("alice", "1"); err != ErrCharacterDead {
+		t.Errorf("SpellTargets error = %v, want %v", err, ErrCharacterDead)
+	}
+}
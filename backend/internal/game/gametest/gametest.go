@@ -0,0 +1,45 @@
+// Package gametest provides the fixtures shared by the tests of the game and
+// of the packages built on it. It does not import game, so that the tests of
+// package game can use it too.
+package gametest
+
+import (
+	"game-server/internal/types"
+	"testing"
+)
+
+// Spells is a catalogue of a single spell, deadly enough to kill a character in one cast
+const Spells = `[{"id": 1, "name": "Execute", "APCost": 3, "range": 2, "maxCastsPerTurn": 1, "damage": 100, "areaOfEffect": "none", "type": "Melee"}]`
+
+// BoardMap returns a small board of two teams: team A is placed on the top
+// row, team B on the bottom row, with an empty row between them
+func BoardMap() types.BoardMap {
+	return types.BoardMap{ID: "test", Name: "Test", Rows: []string{
+		"AAAAA",
+		".....",
+		"BBBBB",
+	}}
+}
+
+// Placer chooses the placement cells of characters, as the game manager does
+type Placer interface {
+	GetCurrentState() *types.GameState
+	SetChosenInitialPosition(userID string, position types.Position) error
+}
+
+// Place chooses the first cell offered to a player's character that no other
+// player chose, and returns it
+func Place(t *testing.T, placer Placer, userID string) types.Position {
+	t.Helper()
+	player, exists := placer.GetCurrentState().Players[userID]
+	if !exists || player.Character == nil {
+		t.Fatalf("player %s has no character to place", userID)
+	}
+	for _, position := range player.Character.InitialPositions {
+		if position != nil && placer.SetChosenInitialPosition(userID, *position) == nil {
+			return *position
+		}
+	}
+	t.Fatalf("no free cell offered to %s", userID)
+	return types.Position{}
+}
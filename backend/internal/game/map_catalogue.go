@@ -0,0 +1,92 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"game-server/internal/types"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DefaultMapID is the map used by rooms that do not pick one
+const DefaultMapID = "default"
+
+var ErrUnknownMap = &RuleError{Code: types.ReasonUnknownMap, Message: "unknown map"}
+
+// MapCatalogue holds the boards rooms can be played on, as loaded from map files.
+type MapCatalogue struct {
+	boards map[string]*Board
+}
+
+// LoadMapCatalogue reads and validates every JSON map file of a directory
+func LoadMapCatalogue(dir string) (*MapCatalogue, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list map files: %w", err)
+	}
+
+	catalogue := &MapCatalogue{boards: make(map[string]*Board)}
+	var errs []error
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read map %s: %w", path, err))
+			continue
+		}
+		board, err := ParseMap(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("map %s: %w", path, err))
+			continue
+		}
+		if _, duplicate := catalogue.boards[board.layout.ID]; duplicate {
+			errs = append(errs, fmt.Errorf("map %s: duplicate id %q", path, board.layout.ID))
+			continue
+		}
+		catalogue.boards[board.layout.ID] = board
+	}
+
+	if _, exists := catalogue.boards[DefaultMapID]; !exists {
+		errs = append(errs, fmt.Errorf("no %q map in %s", DefaultMapID, dir))
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid map catalogue: %w", errors.Join(errs...))
+	}
+	return catalogue, nil
+}
+
+// ParseMap decodes a JSON map and builds its board
+func ParseMap(data []byte) (*Board, error) {
+	var layout types.BoardMap
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return nil, fmt.Errorf("failed to parse map: %w", err)
+	}
+	if layout.ID == "" {
+		return nil, errors.New("id is required")
+	}
+	return NewBoard(layout)
+}
+
+// Get returns the board of the map with the given ID
+func (c *MapCatalogue) Get(mapID string) (*Board, bool) {
+	board, exists := c.boards[mapID]
+	return board, exists
+}
+
+// Default returns the board used by rooms that do not pick a map
+func (c *MapCatalogue) Default() *Board {
+	return c.boards[DefaultMapID]
+}
+
+// List returns the maps ordered by ID
+func (c *MapCatalogue) List() []types.BoardMap {
+	maps := make([]types.BoardMap, 0, len(c.boards))
+	for _, board := range c.boards {
+		maps = append(maps, board.layout)
+	}
+	sort.Slice(maps, func(i, j int) bool {
+		return maps[i].ID < maps[j].ID
+	})
+	return maps
+}
@@ -0,0 +1,88 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// defaultMapFile is a valid default map, which every catalogue needs
+const defaultMapFile = `{"id": "default", "name": "Default", "rows": ["AAA", "...", "BBB"]}`
+
+// writeMaps writes map files, keyed by file name, to a new directory
+func writeMaps(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write map %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoadMapCatalogue(t *testing.T) {
+	dir := writeMaps(t, map[string]string{
+		"default.json": defaultMapFile,
+		"ruins.json":   `{"id": "ruins", "name": "Ruins", "rows": ["AA#", ".o.", "#BB"]}`,
+		"notes.txt":    "not a map",
+	})
+
+	catalogue, err := LoadMapCatalogue(dir)
+	if err != nil {
+		t.Fatalf("LoadMapCatalogue: %v", err)
+	}
+	maps := catalogue.List()
+	if len(maps) != 2 || maps[0].ID != "default" || maps[1].ID != "ruins" {
+		t.Errorf("List = %v, want default and ruins", maps)
+	}
+	if board, exists := catalogue.Get("ruins"); !exists || len(board.PlacementGroups()) != 2 {
+		t.Errorf("Get(ruins) = %v, %v", board, exists)
+	}
+	if catalogue.Default() == nil {
+		t.Error("no default board")
+	}
+}
+
+func TestLoadMapCatalogueRejectsInvalidMaps(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"malformed JSON", map[string]string{"broken.json": `{"id": "broken", "rows": [`}, "failed to parse map"},
+		{"missing id", map[string]string{"anonymous.json": `{"rows": ["A.B"]}`}, "id is required"},
+		{"unknown cell", map[string]string{"swamp.json": `{"id": "swamp", "rows": ["A~B"]}`}, "unknown cell"},
+		{"single team", map[string]string{"solo.json": `{"id": "solo", "rows": ["AAA", "..."]}`}, "at least two teams"},
+		{"no placement cells", map[string]string{"empty.json": `{"id": "empty", "rows": ["...", "..."]}`}, "at least two teams"},
+		{"duplicate id", map[string]string{"copy.json": `{"id": "default", "rows": ["A.B"]}`}, `duplicate id "default"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.files["default.json"] = defaultMapFile
+			_, err := LoadMapCatalogue(writeMaps(t, test.files))
+			if err == nil {
+				t.Fatal("LoadMapCatalogue accepted the maps")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error = %q, want it to mention %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadMapCatalogueRequiresADefaultMap(t *testing.T) {
+	dir := writeMaps(t, map[string]string{
+		"ruins.json": `{"id": "ruins", "rows": ["A.B"]}`,
+	})
+	if _, err := LoadMapCatalogue(dir); err == nil || !strings.Contains(err.Error(), `no "default" map`) {
+		t.Errorf("LoadMapCatalogue error = %v, want a missing default map", err)
+	}
+}
+
+func TestLoadShippedMaps(t *testing.T) {
+	if _, err := LoadMapCatalogue("../../data/maps"); err != nil {
+		t.Fatalf("LoadMapCatalogue: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package game
+
+import (
+	"game-server/internal/types"
+)
+
+var (
+	ErrOffBoard     = &RuleError{Code: types.ReasonOffBoard, Message: "target cell is off the board"}
+	ErrCellOccupied = &RuleError{Code: types.ReasonCellOccupied, Message: "target cell is occupied"}
+	ErrNotWalkable  = &RuleError{Code: types.ReasonNotWalkable, Message: "target cell is an obstacle or a hole"}
+	ErrNoPath       = &RuleError{Code: types.ReasonNoPath, Message: "no path to target cell"}
+	ErrNotEnoughMP  = &RuleError{Code: types.ReasonNotEnoughMP, Message: "not enough movement points"}
+)
+
+// Orthogonal moves only, in a fixed order so that paths are deterministic.
+var neighbourOffsets = []types.Position{
+	{X: 1, Y: 0},
+	{X: -1, Y: 0},
+	{X: 0, Y: 1},
+	{X: 0, Y: -1},
+}
+
+// manhattanDistance returns the number of orthogonal steps between two cells
+func manhattanDistance(a, b types.Position) int {
+	return abs(a.X-b.X) + abs(a.Y-b.Y)
+}
+
+// ReachableCells returns the walkable cells reachable from start in at most
+// maxSteps orthogonal moves, with the number of moves each one costs. The start
+// cell is not included.
+func ReachableCells(board *Board, start types.Position, maxSteps int, isBlocked func(types.Position) bool) map[types.Position]int {
+	costs := map[types.Position]int{start: 0}
+	queue := []types.Position{start}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if costs[current] == maxSteps {
+			continue
+		}
+		for _, offset := range neighbourOffsets {
+			next := types.Position{X: current.X + offset.X, Y: current.Y + offset.Y}
+			if _, seen := costs[next]; seen {
+				continue
+			}
+			if !board.IsWalkable(next) || isBlocked(next) {
+				continue
+			}
+			costs[next] = costs[current] + 1
+			queue = append(queue, next)
+		}
+	}
+
+	delete(costs, start)
+	return costs
+}
+
+// FindPath runs a breadth-first search from start to target over the walkable
+// cells of the board, treating cells for which isBlocked returns true as impassable.
+// The returned path excludes start and ends with target, so its length is the
+// number of movement points the move costs.
+func FindPath(board *Board, start, target types.Position, isBlocked func(types.Position) bool) ([]types.Position, error) {
+	if !board.Contains(target) {
+		return nil, ErrOffBoard
+	}
+	if !board.IsWalkable(target) {
+		return nil, ErrNotWalkable
+	}
+	if start == target {
+		return []types.Position{}, nil
+	}
+	if isBlocked(target) {
+		return nil, ErrCellOccupied
+	}
+
+	cameFrom := map[types.Position]types.Position{start: start}
+	queue := []types.Position{start}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if current == target {
+			break
+		}
+
+		for _, offset := range neighbourOffsets {
+			next := types.Position{X: current.X + offset.X, Y: current.Y + offset.Y}
+			if _, seen := cameFrom[next]; seen {
+				continue
+			}
+			if !board.IsWalkable(next) || isBlocked(next) {
+				continue
+			}
+			cameFrom[next] = current
+			queue = append(queue, next)
+		}
+	}
+
+	if _, reached := cameFrom[target]; !reached {
+		return nil, ErrNoPath
+	}
+
+	// Walk back from the target to rebuild the path
+	var path []types.Position
+	for current := target; current != start; current = cameFrom[current] {
+		path = append([]types.Position{current}, path...)
+	}
+	return path, nil
+}
@@ -0,0 +1,120 @@
+package game
+
+import (
+	"game-server/internal/types"
+	"reflect"
+	"testing"
+)
+
+// pathfindingRows is a board with a wall to walk around and a hole
+var pathfindingRows = []string{
+	"A.#..",
+	"..#..",
+	".....",
+	"o...B",
+}
+
+func TestFindPath(t *testing.T) {
+	board := mustBoard(t, pathfindingRows...)
+	start := types.Position{X: 0, Y: 0}
+
+	tests := []struct {
+		name       string
+		target     types.Position
+		characters []types.Position
+		wantCost   int
+		wantErr    error
+	}{
+		{"same cell", start, nil, 0, nil},
+		{"straight line", types.Position{X: 1, Y: 1}, nil, 2, nil},
+		{"around the wall", types.Position{X: 3, Y: 0}, nil, 7, nil},
+		{"around the wall and a character", types.Position{X: 3, Y: 0}, []types.Position{{X: 2, Y: 2}}, 9, nil},
+		{"characters close the way", types.Position{X: 3, Y: 0}, []types.Position{{X: 2, Y: 2}, {X: 2, Y: 3}}, 0, ErrNoPath},
+		{"occupied target", types.Position{X: 3, Y: 0}, []types.Position{{X: 3, Y: 0}}, 0, ErrCellOccupied},
+		{"obstacle", types.Position{X: 2, Y: 0}, nil, 0, ErrNotWalkable},
+		{"hole", types.Position{X: 0, Y: 3}, nil, 0, ErrNotWalkable},
+		{"off the board", types.Position{X: 5, Y: 0}, nil, 0, ErrOffBoard},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			occupied := make(map[types.Position]bool)
+			for _, position := range test.characters {
+				occupied[position] = true
+			}
+			path, err := FindPath(board, start, test.target, func(pos types.Position) bool {
+				return occupied[pos]
+			})
+			if err != test.wantErr {
+				t.Fatalf("error = %v, want %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(path) != test.wantCost {
+				t.Errorf("path %v costs %d, want %d", path, len(path), test.wantCost)
+			}
+			// Every step is orthogonal, onto a free walkable cell, and the path ends on the target
+			previous := start
+			for _, step := range path {
+				if manhattanDistance(previous, step) != 1 || !board.IsWalkable(step) || occupied[step] {
+					t.Fatalf("path %v has an invalid step to %v", path, step)
+				}
+				previous = step
+			}
+			if previous != test.target {
+				t.Errorf("path %v ends on %v, want %v", path, previous, test.target)
+			}
+		})
+	}
+}
+
+func TestReachableCells(t *testing.T) {
+	board := mustBoard(t, pathfindingRows...)
+	start := types.Position{X: 0, Y: 0}
+
+	tests := []struct {
+		name       string
+		maxSteps   int
+		characters []types.Position
+		want       map[types.Position]int
+	}{
+		{"no movement points", 0, nil, map[types.Position]int{}},
+		{"two steps", 2, nil, map[types.Position]int{
+			{X: 1, Y: 0}: 1, {X: 0, Y: 1}: 1,
+			{X: 1, Y: 1}: 2, {X: 0, Y: 2}: 2,
+		}},
+		{"blocked by a character", 2, []types.Position{{X: 0, Y: 1}}, map[types.Position]int{
+			{X: 1, Y: 0}: 1,
+			{X: 1, Y: 1}: 2,
+		}},
+		{"hole stops the way down", 3, []types.Position{{X: 1, Y: 0}}, map[types.Position]int{
+			{X: 0, Y: 1}: 1,
+			{X: 1, Y: 1}: 2, {X: 0, Y: 2}: 2,
+			{X: 1, Y: 2}: 3,
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			occupied := make(map[types.Position]bool)
+			for _, position := range test.characters {
+				occupied[position] = true
+			}
+			got := ReachableCells(board, start, test.maxSteps, func(pos types.Position) bool {
+				return occupied[pos]
+			})
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ReachableCells = %v, want %v", got, test.want)
+			}
+
+			// Each cost is the length of the shortest path
+			for position, cost := range got {
+				path, err := FindPath(board, start, position, func(pos types.Position) bool {
+					return occupied[pos]
+				})
+				if err != nil || len(path) != cost {
+					t.Errorf("cell %v costs %d, but FindPath gives %v, %v", position, cost, path, err)
+				}
+			}
+		})
+	}
+}
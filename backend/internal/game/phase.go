@@ -0,0 +1,88 @@
+package game
+
+import (
+	"fmt"
+	"game-server/internal/types"
+)
+
+// Game phases, sent to clients as the game status.
+// A game goes lobby -> placement -> fighting -> finished and back to lobby.
+const (
+	PhaseLobby     = "creating_player"
+	PhasePlacement = "position_characters"
+	PhaseFighting  = "playing"
+	PhaseFinished  = "game_over"
+)
+
+// phaseTransitions lists the phases each phase can move to
+var phaseTransitions = map[string][]string{
+	PhaseLobby:     {PhasePlacement},
+	PhasePlacement: {PhaseFighting},
+	PhaseFighting:  {PhaseFinished},
+	PhaseFinished:  {PhaseLobby},
+}
+
+// phaseMessageTypes lists the room message types accepted in each phase
+var phaseMessageTypes = map[string]map[string]bool{
+	PhaseLobby: {
+		"chat":             true,
+		"disconnect":       true,
+		"sync_mode":        true,
+		"sync_request":     true,
+		"create_character": true,
+		"join_team":        true,
+		"add_bot":          true,
+		"remove_bot":       true,
+		"ready_to_start":   true,
+	},
+	PhasePlacement: {
+		"chat":                 true,
+		"disconnect":           true,
+		"sync_mode":            true,
+		"sync_request":         true,
+		"character_positioned": true,
+	},
+	PhaseFighting: {
+		"chat":                true,
+		"disconnect":          true,
+		"sync_mode":           true,
+		"sync_request":        true,
+		"move":                true,
+		"cast_spell":          true,
+		"end_turn":            true,
+		"get_reachable_cells": true,
+		"get_spell_targets":   true,
+	},
+	PhaseFinished: {
+		"chat":            true,
+		"disconnect":      true,
+		"sync_mode":       true,
+		"sync_request":    true,
+		"return_to_lobby": true,
+	},
+}
+
+var ErrWrongPhase = &RuleError{Code: types.ReasonWrongPhase, Message: "action not allowed in the current game phase"}
+
+// CanTransition reports whether a game can move from one phase to another
+func CanTransition(from, to string) bool {
+	for _, next := range phaseTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMessageAllowed reports whether a message type can be handled during a phase
+func IsMessageAllowed(phase, messageType string) bool {
+	return phaseMessageTypes[phase][messageType]
+}
+
+// checkTransition returns an error if the transition between phases is not legal
+func checkTransition(from, to string) error {
+	if !CanTransition(from, to) {
+		return fmt.Errorf("illegal game phase transition from %q to %q", from, to)
+	}
+	return nil
+}
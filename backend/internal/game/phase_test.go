@@ -0,0 +1,43 @@
+package game
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	phases := []string{PhaseLobby, PhasePlacement, PhaseFighting, PhaseFinished}
+	legal := map[[2]string]bool{
+		{PhaseLobby, PhasePlacement}:    true,
+		{PhasePlacement, PhaseFighting}: true,
+		{PhaseFighting, PhaseFinished}:  true,
+		{PhaseFinished, PhaseLobby}:     true,
+	}
+	for _, from := range phases {
+		for _, to := range phases {
+			if got := CanTransition(from, to); got != legal[[2]string{from, to}] {
+				t.Errorf("CanTransition(%s, %s) = %t, want %t", from, to, got, !got)
+			}
+		}
+	}
+}
+
+func TestIsMessageAllowed(t *testing.T) {
+	tests := []struct {
+		phase, messageType string
+		want               bool
+	}{
+		{PhaseLobby, "create_character", true},
+		{PhaseLobby, "move", false},
+		{PhasePlacement, "character_positioned", true},
+		{PhasePlacement, "ready_to_start", false},
+		{PhaseFighting, "cast_spell", true},
+		{PhaseFighting, "create_character", false},
+		{PhaseFinished, "return_to_lobby", true},
+		{PhaseFinished, "end_turn", false},
+		{PhaseFighting, "chat", true},
+		{"unknown", "chat", false},
+	}
+	for _, test := range tests {
+		if got := IsMessageAllowed(test.phase, test.messageType); got != test.want {
+			t.Errorf("IsMessageAllowed(%s, %s) = %t, want %t", test.phase, test.messageType, got, test.want)
+		}
+	}
+}
@@ -3,10 +3,31 @@ package game
 import (
 	"fmt"
 	"game-server/internal/types"
-	"log"
 	"sync"
 )
 
+// Default character stats, restored at the start of each turn or game
+const (
+	DefaultHealth         = 100
+	DefaultActionPoints   = 6
+	DefaultMovementPoints = 4
+)
+
+// NewCharacter returns a fresh character with the default stats. Only its name
+// and look are chosen by the player.
+func NewCharacter(name string, color string, symbol string) *types.Character {
+	return &types.Character{
+		Name:           name,
+		Color:          color,
+		Symbol:         symbol,
+		ActionPoints:   DefaultActionPoints,
+		MovementPoints: DefaultMovementPoints,
+		Health:         DefaultHealth,
+		IsAlive:        true,
+		Initiative:     DefaultInitiative,
+	}
+}
+
 type PlayerManager struct {
 	players map[string]types.Player
 	mutex   sync.Mutex
@@ -44,18 +65,6 @@ func (pm *PlayerManager) GetPlayers() map[string]types.Player {
 	return players
 }
 
-func (pm *PlayerManager) GetUserIDWithCharacterName(characterName string) string {
-	pm.mutex.Lock()
-	defer pm.mutex.Unlock()
-
-	for userID, player := range pm.players {
-		if player.Character.Name == characterName {
-			return userID
-		}
-	}
-	return ""
-}
-
 func (pm *PlayerManager) GetPlayer(userID string) (types.Player, bool) {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
@@ -64,88 +73,55 @@ func (pm *PlayerManager) GetPlayer(userID string) (types.Player, bool) {
 	return player, ok
 }
 
-func (pm *PlayerManager) PlayerReadyToStart(readyMessage types.IsReadyMessage) {
-	pm.mutex.Lock()
-	defer pm.mutex.Unlock()
-
-	if player, ok := pm.players[readyMessage.UserID]; ok {
-		player.IsReady = true
-		pm.players[readyMessage.UserID] = player
-	}
-}
-
-func (pm *PlayerManager) ResetMPs(UserID string) error {
-	pm.mutex.Lock()
-	defer pm.mutex.Unlock()
-
-	player, ok := pm.players[UserID]
-	if !ok {
-		return fmt.Errorf("player with ID %s not found", UserID)
-	}
-	player.Character.MovementPoints = 4 // Reset the character's movement points to the default value
-	pm.players[UserID] = player
-	log.Printf("[Debug] Reset MP for player %s to %d", UserID, player.Character.MovementPoints)
-	return nil
-}
-
-func (pm *PlayerManager) SetFirstCharacter() {
-	players := pm.GetPlayers()
-
-	// First player in the map
-	var firstPlayerID string
-	for k := range players {
-		firstPlayerID = k
-		break
-	}
-
-	// Define the first Player character as the current turn
-	firstPlayer := players[firstPlayerID]
-	firstPlayer.Character.IsCurrentTurn = true
-	firstPlayer.IsCurrentTurn = true
-
-	pm.UpdatePlayer(firstPlayerID, firstPlayer)
-}
-
-func (pm *PlayerManager) SetPlayerCurrentTurn(userID string, isCurrentTurn bool) error {
+// SetPlayerTeam moves a player to another team
+func (pm *PlayerManager) SetPlayerTeam(userID string, team string) error {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
 
-	log.Printf("[Debug] Setting player %s current turn to %t", userID, isCurrentTurn)
 	player, ok := pm.players[userID]
 	if !ok {
-		return fmt.Errorf("player with ID %s not found", userID)
+		return fmt.Errorf("player %s not found", userID)
 	}
-
-	player.IsCurrentTurn = isCurrentTurn
+	player.Team = team
 	pm.players[userID] = player
 	return nil
 }
 
-func (pm *PlayerManager) SetPlayerHasPositioned(userID string, hasPositioned bool) error {
+func (pm *PlayerManager) PlayerReadyToStart(userID string) {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
 
-	player, ok := pm.players[userID]
-	if !ok {
-		return fmt.Errorf("player with ID %s not found", userID)
+	if player, ok := pm.players[userID]; ok {
+		player.IsReady = true
+		pm.players[userID] = player
 	}
-
-	player.HasPositioned = hasPositioned
-	pm.players[userID] = player
-	return nil
 }
 
-func (pm *PlayerManager) ResetAPs(userID string) error {
+// ResetForNewGame puts every player back in the lobby with a fresh character,
+// keeping the character's name and look
+func (pm *PlayerManager) ResetForNewGame() {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
 
-	player, ok := pm.players[userID]
-	if !ok {
-		return fmt.Errorf("player with ID %s not found", userID)
+	for userID, player := range pm.players {
+		player.IsReady = player.IsBot
+		player.HasPositioned = false
+		player.IsCurrentTurn = false
+		player.Status = "waiting-room"
+
+		if player.Character != nil {
+			player.Character.Health = DefaultHealth
+			player.Character.IsAlive = true
+			player.Character.ActionPoints = DefaultActionPoints
+			player.Character.MovementPoints = DefaultMovementPoints
+			player.Character.Position = nil
+			player.Character.InitialPositions = nil
+			player.Character.IsCurrentTurn = false
+			player.Character.HasPlayedThisTurn = false
+			player.Character.SpellCooldowns = nil
+			player.Character.SpellCastsThisTurn = nil
+			player.Character.Effects = nil
+		}
+		pm.players[userID] = player
 	}
-
-	player.Character.ActionPoints = 6 // Reset AP to default value
-	pm.players[userID] = player
-	log.Printf("[Debug] Reset AP for player %s to %d", userID, player.Character.ActionPoints)
-	return nil
 }
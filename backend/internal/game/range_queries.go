@@ -0,0 +1,104 @@
+package game
+
+import (
+	"game-server/internal/types"
+	"sort"
+)
+
+// The queries below answer what a player could do from where its character stands,
+// with the same rules as the actions, so that clients highlight cells from them
+// instead of computing ranges on their own.
+
+// MoveRange returns the cells a player's character can walk to with its remaining
+// movement points, cheapest first
+func (gm *GameManager) MoveRange(playerID string) ([]types.ReachableCell, error) {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	player, err := gm.fighter(playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	occupied := gm.occupiedCells(playerID)
+	reachable := ReachableCells(gm.board, *player.Character.Position, player.Character.MovementPoints, func(pos types.Position) bool {
+		return occupied[pos]
+	})
+
+	cells := make([]types.ReachableCell, 0, len(reachable))
+	for position, cost := range reachable {
+		cells = append(cells, types.ReachableCell{Position: position, Cost: cost})
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Cost != cells[j].Cost {
+			return cells[i].Cost < cells[j].Cost
+		}
+		return positionLess(cells[i].Position, cells[j].Position)
+	})
+	return cells, nil
+}
+
+// SpellTargets returns the cells a player's character can cast a spell on from
+// where it stands, whether or not it has the AP to cast it now
+func (gm *GameManager) SpellTargets(playerID string, spellID string) ([]types.Position, error) {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	caster, spell, err := gm.casterSpell(playerID, spellID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every target in range lies in the diamond of the spell's range around the caster
+	from := *caster.Character.Position
+	targets := []types.Position{}
+	for dx := -spell.Range; dx <= spell.Range; dx++ {
+		for dy := abs(dx) - spell.Range; dy <= spell.Range-abs(dx); dy++ {
+			target := types.Position{X: from.X + dx, Y: from.Y + dy}
+			if gm.checkSpellTargetFrom(playerID, spell, from, target) == nil {
+				targets = append(targets, target)
+			}
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return positionLess(targets[i], targets[j])
+	})
+	return targets, nil
+}
+
+// PreviewSpell returns what a cast of a player's spell on the target cell would
+// do: the cells of the board it covers, and the damage it would deal to each
+// character hit, critical hits aside. The target is not checked.
+func (gm *GameManager) PreviewSpell(playerID string, spellID string, target types.Position) ([]types.Position, []types.SpellHit, error) {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	caster, spell, err := gm.casterSpell(playerID, spellID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	area := gm.spellArea(spell, *caster.Character.Position, target)
+	hits := []types.SpellHit{}
+	for _, position := range area {
+		for _, userID := range gm.charactersHit(caster, position) {
+			character := gm.fold.state.Players[userID].Character
+			dealt := damageTaken(character, spell.Damage)
+			hits = append(hits, types.SpellHit{
+				UserID:   userID,
+				Position: position,
+				Damage:   dealt,
+				IsDead:   dealt >= character.Health,
+			})
+		}
+	}
+	return area, hits, nil
+}
+
+// positionLess orders cells row by row
+func positionLess(a, b types.Position) bool {
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+	return a.X < b.X
+}
@@ -0,0 +1,166 @@
+package game
+
+import (
+	"game-server/internal/types"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// A ranged spell that can land on empty cells, a melee spell that must hit a
+// character and a cross shaped area spell
+const rangeSpells = `[
+	{"id": 1, "name": "Bolt", "APCost": 2, "range": 2, "needsLineOfSight": true, "castOnEmptyCell": true, "damage": 10, "areaOfEffect": "none", "type": "Fire"},
+	{"id": 2, "name": "Jab", "APCost": 2, "range": 1, "damage": 10, "areaOfEffect": "none", "type": "Melee"},
+	{"id": 3, "name": "Wave", "APCost": 3, "range": 3, "castOnEmptyCell": true, "damage": 20, "areaOfEffect": "cross", "type": "Water"}
+]`
+
+// newRangeFight starts a fight between alice, of team A, and bob, of team B, on
+// a board with a single placement cell per team
+func newRangeFight(t *testing.T, rows ...string) *GameManager {
+	t.Helper()
+	spells, err := ParseSpellCatalogue([]byte(rangeSpells))
+	if err != nil {
+		t.Fatalf("failed to parse spells: %v", err)
+	}
+	gm := NewGameManager(spells, mustBoard(t, rows...))
+	startTestFight(t, gm, readyPlayers())
+	return gm
+}
+
+func TestMoveRange(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []string
+		want []types.ReachableCell
+	}{
+		{
+			"walks up to its movement points",
+			[]string{"A......B"},
+			[]types.ReachableCell{
+				{Position: types.Position{X: 1, Y: 0}, Cost: 1},
+				{Position: types.Position{X: 2, Y: 0}, Cost: 2},
+				{Position: types.Position{X: 3, Y: 0}, Cost: 3},
+				{Position: types.Position{X: 4, Y: 0}, Cost: 4},
+			},
+		},
+		{
+			"goes around obstacles, holes and characters",
+			[]string{
+				"A.#",
+				".o.",
+				"..B",
+			},
+			[]types.ReachableCell{
+				{Position: types.Position{X: 1, Y: 0}, Cost: 1},
+				{Position: types.Position{X: 0, Y: 1}, Cost: 1},
+				{Position: types.Position{X: 0, Y: 2}, Cost: 2},
+				{Position: types.Position{X: 1, Y: 2}, Cost: 3},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gm := newRangeFight(t, test.rows...)
+			cells, err := gm.MoveRange("alice")
+			if err != nil {
+				t.Fatalf("MoveRange: %v", err)
+			}
+			if !reflect.DeepEqual(cells, test.want) {
+				t.Errorf("MoveRange = %v, want %v", cells, test.want)
+			}
+		})
+	}
+}
+
+func TestSpellTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    []string
+		spellID string
+		want    []types.Position
+	}{
+		{
+			"empty cells in range and in sight",
+			[]string{
+				"A#...",
+				".....",
+				"....B",
+			},
+			"1",
+			[]types.Position{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 0, Y: 2}},
+		},
+		{
+			"characters in range",
+			[]string{"AB."},
+			"2",
+			[]types.Position{{X: 0, Y: 0}, {X: 1, Y: 0}},
+		},
+		{
+			"no character in range",
+			[]string{"A.B"},
+			"2",
+			[]types.Position{{X: 0, Y: 0}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gm := newRangeFight(t, test.rows...)
+			targets, err := gm.SpellTargets("alice", test.spellID)
+			if err != nil {
+				t.Fatalf("SpellTargets: %v", err)
+			}
+			if !reflect.DeepEqual(targets, test.want) {
+				t.Errorf("SpellTargets = %v, want %v", targets, test.want)
+			}
+		})
+	}
+
+	gm := newRangeFight(t, "A.B")
+	if _, err := gm.SpellTargets("alice", "9"); err != ErrUnknownSpell {
+		t.Errorf("SpellTargets of an unknown spell = %v, want %v", err, ErrUnknownSpell)
+	}
+}
+
+func TestPreviewSpell(t *testing.T) {
+	gm := newRangeFight(t,
+		"A....",
+		".....",
+		"..B..",
+	)
+	bob := types.Position{X: 2, Y: 2}
+
+	area, hits, err := gm.PreviewSpell("alice", "3", bob)
+	if err != nil {
+		t.Fatalf("PreviewSpell: %v", err)
+	}
+	// The cross is cut by the bottom edge of the board
+	sort.Slice(area, func(i, j int) bool {
+		return positionLess(area[i], area[j])
+	})
+	wantArea := []types.Position{{X: 2, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 3, Y: 2}}
+	if !reflect.DeepEqual(area, wantArea) {
+		t.Errorf("area = %v, want %v", area, wantArea)
+	}
+	wantHits := []types.SpellHit{{UserID: "bob", Position: bob, Damage: 20}}
+	if !reflect.DeepEqual(hits, wantHits) {
+		t.Errorf("hits = %+v, want %+v", hits, wantHits)
+	}
+
+	// A vulnerable character low on health would die
+	gm.mutex.Lock()
+	gm.record(&DamageApplied{SourceID: "alice", TargetID: "bob", Amount: DefaultHealth - 25})
+	gm.record(&EffectApplied{TargetID: "bob", Effect: types.StatusEffect{Kind: EffectVulnerability, Value: 50, RemainingTurns: 1, SourceUserID: "alice", SpellID: 3}})
+	gm.mutex.Unlock()
+	_, hits, err = gm.PreviewSpell("alice", "3", bob)
+	if err != nil {
+		t.Fatalf("PreviewSpell: %v", err)
+	}
+	wantHits = []types.SpellHit{{UserID: "bob", Position: bob, Damage: 30, IsDead: true}}
+	if !reflect.DeepEqual(hits, wantHits) {
+		t.Errorf("hits = %+v, want %+v", hits, wantHits)
+	}
+	if health := gm.GetCurrentState().Players["bob"].Character.Health; health != 25 {
+		t.Errorf("bob's health after the preview = %d, want 25", health)
+	}
+}
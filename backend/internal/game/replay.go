@@ -0,0 +1,87 @@
+package game
+
+import (
+	"errors"
+	"game-server/internal/types"
+)
+
+// ReplayVersion is the version of the replay format, raised on incompatible changes
+const ReplayVersion = 1
+
+// Replay is a game in a form that can be downloaded and played back: the seed
+// its random source was given when the game started, its state when the lobby
+// closed, and every event that followed, in order.
+type Replay struct {
+	Version      int              `json:"version"`
+	GameID       string           `json:"gameId"`
+	Seed         int64            `json:"seed"`
+	InitialState *types.GameState `json:"initialState"`
+	Events       []EventRecord    `json:"events"`
+}
+
+// Replay exports the current game, which must have left the lobby, as a replay
+func (gm *GameManager) Replay(gameID string) (Replay, error) {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	for i, record := range gm.events {
+		if started, ok := record.Event.(*GameStarted); ok {
+			return Replay{
+				Version:      ReplayVersion,
+				GameID:       gameID,
+				Seed:         started.Seed,
+				InitialState: Fold(gm.events[:i+1]),
+				Events:       append([]EventRecord(nil), gm.events[i+1:]...),
+			}, nil
+		}
+	}
+	return Replay{}, errors.New("the game has not started")
+}
+
+// ReplayPlayer plays a replay back one event at a time
+type ReplayPlayer struct {
+	events []EventRecord
+	fold   *fold
+	next   int
+}
+
+func NewReplayPlayer(replay Replay) (*ReplayPlayer, error) {
+	if replay.InitialState == nil {
+		return nil, errors.New("the replay has no initial state")
+	}
+
+	f := newFold()
+	state := *replay.InitialState
+	state.Players = make(map[string]types.Player, len(replay.InitialState.Players))
+	for userID, player := range replay.InitialState.Players {
+		player.Character = copyCharacter(player.Character)
+		state.Players[userID] = player
+	}
+	state.Timeline = append([]string(nil), replay.InitialState.Timeline...)
+	f.state = &state
+
+	return &ReplayPlayer{events: replay.Events, fold: f}, nil
+}
+
+// State returns the state reached so far. It must not be modified.
+func (p *ReplayPlayer) State() *types.GameState {
+	return p.fold.state
+}
+
+// Next returns the event the next step applies, if the replay is not over
+func (p *ReplayPlayer) Next() (EventRecord, bool) {
+	if p.next >= len(p.events) {
+		return EventRecord{}, false
+	}
+	return p.events[p.next], true
+}
+
+// Step applies the next event. It returns false once the replay is over.
+func (p *ReplayPlayer) Step() bool {
+	if p.next >= len(p.events) {
+		return false
+	}
+	p.events[p.next].Event.apply(p.fold)
+	p.next++
+	return true
+}
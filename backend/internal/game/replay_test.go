@@ -0,0 +1,91 @@
+package game
+
+import (
+	"game-server/internal/types"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// A weak spell that lands a critical hit half of the time
+const criticalSpells = `[{"id": 1, "name": "Jab", "APCost": 1, "range": 5, "damage": 1, "criticalChance": 50, "criticalDamage": 2, "areaOfEffect": "none", "type": "Melee"}]`
+
+func TestReplaySeedReproducesTheGame(t *testing.T) {
+	gm := newTestGameManagerWithSpells(t, criticalSpells)
+	startTestFight(t, gm, readyPlayers())
+
+	bob := *gm.GetCurrentState().Players["bob"].Character.Position
+	for i := 0; i < 20; i++ {
+		if _, _, err := gm.CastSpell("alice", "1", bob); err != nil {
+			t.Fatalf("CastSpell: %v", err)
+		}
+	}
+
+	replay, err := gm.Replay("game")
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	rng := rand.New(rand.NewSource(replay.Seed))
+
+	// The placement cells offered when the game started
+	userIDs := make([]string, 0, len(replay.InitialState.Players))
+	for userID := range replay.InitialState.Players {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+	offered := make(map[types.Position]bool)
+	for _, userID := range userIDs {
+		player := replay.InitialState.Players[userID]
+		want := generateInitialPositions(rng, gm.board.PlacementCells(player.Team), offered)
+		if !reflect.DeepEqual(player.Character.InitialPositions, want) {
+			t.Errorf("cells offered to %s = %v, want %v", userID, player.Character.InitialPositions, want)
+		}
+	}
+
+	// Then the turn order and the critical hits
+	casts := 0
+	for _, record := range replay.Events {
+		switch event := record.Event.(type) {
+		case *FightStarted:
+			if want := ComputeTimeline(replay.InitialState.Players, rng); !reflect.DeepEqual(event.Timeline, want) {
+				t.Errorf("timeline = %v, want %v", event.Timeline, want)
+			}
+		case *SpellCast:
+			casts++
+			if want := rng.Intn(100) < 50; event.Critical != want {
+				t.Errorf("cast %d critical = %t, want %t", casts, event.Critical, want)
+			}
+		}
+	}
+	if casts != 20 {
+		t.Errorf("replay has %d casts, want 20", casts)
+	}
+}
+
+func TestEveryGameHasItsOwnSeed(t *testing.T) {
+	gm := newTestGameManager(t)
+	startTestFight(t, gm, readyPlayers())
+	first, err := gm.Replay("first")
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	if err := gm.SetGameStatus(PhaseFinished); err != nil {
+		t.Fatalf("SetGameStatus: %v", err)
+	}
+	if err := gm.ResetToLobby(); err != nil {
+		t.Fatalf("ResetToLobby: %v", err)
+	}
+	if err := gm.StartGame(readyPlayers()); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+	second, err := gm.Replay("second")
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	if first.Seed == second.Seed {
+		t.Errorf("both games were seeded with %d", first.Seed)
+	}
+}
@@ -0,0 +1,36 @@
+package game
+
+import (
+	"errors"
+	"game-server/internal/types"
+)
+
+// RuleError is returned when an action breaks a game rule. Its code is the
+// machine-readable reason sent back to the client that requested the action.
+type RuleError struct {
+	Code    string
+	Message string
+}
+
+func (e *RuleError) Error() string {
+	return e.Message
+}
+
+var (
+	ErrNotYourTurn    = &RuleError{Code: types.ReasonNotYourTurn, Message: "it is not this player's turn"}
+	ErrNotEnoughAP    = &RuleError{Code: types.ReasonNotEnoughAP, Message: "not enough action points"}
+	ErrUnknownSpell   = &RuleError{Code: types.ReasonUnknownSpell, Message: "unknown spell"}
+	ErrPlayerNotFound = &RuleError{Code: types.ReasonPlayerNotFound, Message: "player not found"}
+	ErrNoPosition     = &RuleError{Code: types.ReasonNoPosition, Message: "character has no position"}
+	ErrCharacterDead  = &RuleError{Code: types.ReasonCharacterDead, Message: "character is dead"}
+)
+
+// ReasonCode returns the reason code carried by err, or INTERNAL_ERROR when err
+// is not a rule violation
+func ReasonCode(err error) string {
+	var ruleErr *RuleError
+	if errors.As(err, &ruleErr) {
+		return ruleErr.Code
+	}
+	return types.ReasonInternalError
+}
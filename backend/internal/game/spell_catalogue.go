@@ -0,0 +1,120 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"game-server/internal/types"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// SpellCatalogue holds every spell available in a game, as loaded from a spell file.
+type SpellCatalogue struct {
+	spells []types.Spell
+}
+
+// LoadSpellCatalogue reads and validates a JSON spell catalogue file
+func LoadSpellCatalogue(path string) (*SpellCatalogue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spell catalogue: %w", err)
+	}
+	return ParseSpellCatalogue(data)
+}
+
+// ParseSpellCatalogue decodes a JSON array of spells and validates it
+func ParseSpellCatalogue(data []byte) (*SpellCatalogue, error) {
+	var spells []types.Spell
+	if err := json.Unmarshal(data, &spells); err != nil {
+		return nil, fmt.Errorf("failed to parse spell catalogue: %w", err)
+	}
+
+	if err := validateSpells(spells); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(spells, func(i, j int) bool {
+		return spells[i].ID < spells[j].ID
+	})
+	return &SpellCatalogue{spells: spells}, nil
+}
+
+// validateSpells returns every problem found in the catalogue at once
+func validateSpells(spells []types.Spell) error {
+	var errs []error
+	seenIDs := make(map[int]bool)
+
+	for i, spell := range spells {
+		label := fmt.Sprintf("spell #%d (%q)", i, spell.Name)
+
+		if spell.ID <= 0 {
+			errs = append(errs, fmt.Errorf("%s: id must be positive", label))
+		} else if seenIDs[spell.ID] {
+			errs = append(errs, fmt.Errorf("%s: duplicate id %d", label, spell.ID))
+		}
+		seenIDs[spell.ID] = true
+
+		if spell.Name == "" {
+			errs = append(errs, fmt.Errorf("%s: name is required", label))
+		}
+		if _, known := areaOfEffectPatterns[spell.AreaOfEffect]; !known {
+			errs = append(errs, fmt.Errorf("%s: unknown area of effect %q", label, spell.AreaOfEffect))
+		}
+		if spell.APCost < 0 {
+			errs = append(errs, fmt.Errorf("%s: AP cost must not be negative", label))
+		}
+		if spell.Range < 0 {
+			errs = append(errs, fmt.Errorf("%s: range must not be negative", label))
+		}
+		if spell.MaxCastsPerTurn < 0 {
+			errs = append(errs, fmt.Errorf("%s: max casts per turn must not be negative", label))
+		}
+		if spell.Cooldown < 0 {
+			errs = append(errs, fmt.Errorf("%s: cooldown must not be negative", label))
+		}
+		if spell.CriticalChance < 0 || spell.CriticalChance > 100 {
+			errs = append(errs, fmt.Errorf("%s: critical chance must be between 0 and 100", label))
+		}
+		if spell.CriticalDamage < 0 {
+			errs = append(errs, fmt.Errorf("%s: critical damage must not be negative", label))
+		}
+		for _, effect := range spell.Effects {
+			if err := validateSpellEffect(effect); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", label, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid spell catalogue: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// List returns the spells ordered by ID
+func (c *SpellCatalogue) List() []types.Spell {
+	spells := make([]types.Spell, len(c.spells))
+	copy(spells, c.spells)
+	return spells
+}
+
+// Spells returns the spells keyed by their ID, as stored in the game state
+func (c *SpellCatalogue) Spells() map[string]types.Spell {
+	spells := make(map[string]types.Spell, len(c.spells))
+	for _, spell := range c.spells {
+		spells[strconv.Itoa(spell.ID)] = spell
+	}
+	return spells
+}
+
+// Get returns the spell with the given ID
+func (c *SpellCatalogue) Get(spellID string) (types.Spell, bool) {
+	for _, spell := range c.spells {
+		if strconv.Itoa(spell.ID) == spellID {
+			return spell, true
+		}
+	}
+	return types.Spell{}, false
+}
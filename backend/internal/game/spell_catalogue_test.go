@@ -0,0 +1,72 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSpellCatalogueRejectsInvalidSpells(t *testing.T) {
+	const jab = `{"id": 1, "name": "Jab", "APCost": 2, "range": 1, "damage": 10, "areaOfEffect": "none"}`
+	tests := []struct {
+		name   string
+		spells string
+		want   string
+	}{
+		{"duplicate id", `[` + jab + `, {"id": 1, "name": "Kick", "areaOfEffect": "none"}]`, "duplicate id 1"},
+		{"missing id", `[{"name": "Jab", "areaOfEffect": "none"}]`, "id must be positive"},
+		{"missing name", `[{"id": 1, "areaOfEffect": "none"}]`, "name is required"},
+		{"unknown area", `[{"id": 1, "name": "Jab", "areaOfEffect": "square"}]`, `unknown area of effect "square"`},
+		{"negative AP cost", `[{"id": 1, "name": "Jab", "APCost": -1, "areaOfEffect": "none"}]`, "AP cost must not be negative"},
+		{"negative range", `[{"id": 1, "name": "Jab", "range": -2, "areaOfEffect": "none"}]`, "range must not be negative"},
+		{"negative cooldown", `[{"id": 1, "name": "Jab", "cooldown": -1, "areaOfEffect": "none"}]`, "cooldown must not be negative"},
+		{"critical chance over 100", `[{"id": 1, "name": "Jab", "criticalChance": 101, "areaOfEffect": "none"}]`, "critical chance must be between 0 and 100"},
+		{"unknown effect", `[{"id": 1, "name": "Jab", "areaOfEffect": "none", "effects": [{"kind": "sleep", "duration": 1}]}]`, `unknown effect kind "sleep"`},
+		{"effect without duration", `[{"id": 1, "name": "Jab", "areaOfEffect": "none", "effects": [{"kind": "poison", "value": 5}]}]`, "must last at least one turn"},
+		{"malformed JSON", `[` + jab, "failed to parse spell catalogue"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseSpellCatalogue([]byte(test.spells))
+			if err == nil {
+				t.Fatal("ParseSpellCatalogue accepted the catalogue")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error = %q, want it to mention %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestParseSpellCatalogueReportsEveryProblem(t *testing.T) {
+	_, err := ParseSpellCatalogue([]byte(`[
+		{"id": 1, "name": "Jab", "APCost": -1, "areaOfEffect": "none"},
+		{"id": 1, "name": "Kick", "range": -1, "areaOfEffect": "none"}
+	]`))
+	if err == nil {
+		t.Fatal("ParseSpellCatalogue accepted the catalogue")
+	}
+	for _, want := range []string{"AP cost must not be negative", "duplicate id 1", "range must not be negative"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want it to mention %q", err, want)
+		}
+	}
+}
+
+func TestLoadSpellCatalogue(t *testing.T) {
+	catalogue, err := LoadSpellCatalogue("../../data/spells.json")
+	if err != nil {
+		t.Fatalf("LoadSpellCatalogue: %v", err)
+	}
+	spells := catalogue.List()
+	if len(spells) == 0 {
+		t.Fatal("the shipped catalogue has no spells")
+	}
+	for i := 1; i < len(spells); i++ {
+		if spells[i-1].ID >= spells[i].ID {
+			t.Errorf("spells are not ordered by ID: %d before %d", spells[i-1].ID, spells[i].ID)
+		}
+	}
+	if _, exists := catalogue.Get("1"); !exists {
+		t.Error("Get(1) found no spell")
+	}
+}
@@ -0,0 +1,41 @@
+package game
+
+import (
+	"game-server/internal/types"
+)
+
+var (
+	ErrSpellOnCooldown = &RuleError{Code: types.ReasonSpellOnCooldown, Message: "spell is on cooldown"}
+	ErrMaxCastsReached = &RuleError{Code: types.ReasonMaxCastsReached, Message: "spell cast too many times this turn"}
+)
+
+// CheckSpellUsage makes sure a player's character can cast the spell again:
+// the spell must be off cooldown and under its casts-per-turn limit.
+func (gm *GameManager) CheckSpellUsage(playerID string, spellID string) error {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	currentState := gm.fold.state
+
+	spell, exists := currentState.Spells[spellID]
+	if !exists {
+		return ErrUnknownSpell
+	}
+	player, exists := currentState.Players[playerID]
+	if !exists || player.Character == nil {
+		return ErrPlayerNotFound
+	}
+
+	return spellUsageError(player.Character, spellID, spell)
+}
+
+// spellUsageError returns why a character cannot cast the spell again, if it cannot
+func spellUsageError(character *types.Character, spellID string, spell types.Spell) error {
+	if character.SpellCooldowns[spellID] > 0 {
+		return ErrSpellOnCooldown
+	}
+	if spell.MaxCastsPerTurn > 0 && character.SpellCastsThisTurn[spellID] >= spell.MaxCastsPerTurn {
+		return ErrMaxCastsReached
+	}
+	return nil
+}
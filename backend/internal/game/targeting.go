@@ -0,0 +1,99 @@
+package game
+
+import (
+	"game-server/internal/types"
+)
+
+var (
+	ErrOutOfRange     = &RuleError{Code: types.ReasonOutOfRange, Message: "target is out of range"}
+	ErrNoLineOfSight  = &RuleError{Code: types.ReasonNoLineOfSight, Message: "target is not in line of sight"}
+	ErrNotInLine      = &RuleError{Code: types.ReasonNotInLine, Message: "spell must be cast in a straight line"}
+	ErrTargetNotValid = &RuleError{Code: types.ReasonInvalidTarget, Message: "spell must target a character"}
+	ErrTargetObstacle = &RuleError{Code: types.ReasonInvalidTarget, Message: "spell cannot target an obstacle"}
+)
+
+// CheckSpellTarget checks a cast from caster to target against the spell rules:
+// board bounds, range, cast-in-line, empty cell and line of sight. Obstacles
+// cannot be targeted.
+// occupied tells whether a cell holds a character; isBlocked tells whether a cell
+// blocks the line of sight.
+func CheckSpellTarget(spell types.Spell, board *Board, caster, target types.Position, occupied, isBlocked func(types.Position) bool) error {
+	if !board.Contains(target) {
+		return ErrOffBoard
+	}
+	if board.BlocksLineOfSight(target) {
+		return ErrTargetObstacle
+	}
+
+	if manhattanDistance(caster, target) > spell.Range {
+		return ErrOutOfRange
+	}
+
+	if spell.CastInLineOnly && caster.X != target.X && caster.Y != target.Y {
+		return ErrNotInLine
+	}
+
+	if !spell.CastOnEmptyCell && !occupied(target) {
+		return ErrTargetNotValid
+	}
+
+	if spell.NeedsLineOfSight && !HasLineOfSight(caster, target, isBlocked) {
+		return ErrNoLineOfSight
+	}
+
+	return nil
+}
+
+// HasLineOfSight reports whether no blocking cell lies strictly between from and to.
+func HasLineOfSight(from, to types.Position, isBlocked func(types.Position) bool) bool {
+	for _, cell := range lineCells(from, to) {
+		if isBlocked(cell) {
+			return false
+		}
+	}
+	return true
+}
+
+// lineCells returns the cells crossed by the segment joining the centres of from
+// and to, excluding both ends. When the segment passes exactly through a corner
+// it steps diagonally, so the two cells touching that corner do not block.
+func lineCells(from, to types.Position) []types.Position {
+	dx := to.X - from.X
+	dy := to.Y - from.Y
+	nx, ny := abs(dx), abs(dy)
+	stepX, stepY := sign(dx), sign(dy)
+
+	var cells []types.Position
+	current := from
+	for ix, iy := 0, 0; ix < nx || iy < ny; {
+		// Compare (0.5+ix)/nx with (0.5+iy)/ny without floating point
+		decision := (1+2*ix)*ny - (1+2*iy)*nx
+		switch {
+		case decision == 0:
+			current.X += stepX
+			current.Y += stepY
+			ix++
+			iy++
+		case decision < 0:
+			current.X += stepX
+			ix++
+		default:
+			current.Y += stepY
+			iy++
+		}
+		if current != to {
+			cells = append(cells, current)
+		}
+	}
+	return cells
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
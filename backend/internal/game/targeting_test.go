@@ -0,0 +1,84 @@
+package game
+
+import (
+	"game-server/internal/types"
+	"testing"
+)
+
+func TestLineOfSight(t *testing.T) {
+	board := mustBoard(t,
+		"A....",
+		"o.#..",
+		"....B",
+	)
+
+	tests := []struct {
+		name       string
+		from, to   types.Position
+		characters []types.Position
+		want       bool
+	}{
+		{"clear row", types.Position{X: 0, Y: 0}, types.Position{X: 4, Y: 0}, nil, true},
+		{"obstacle between", types.Position{X: 1, Y: 1}, types.Position{X: 4, Y: 1}, nil, false},
+		{"holes do not block", types.Position{X: 0, Y: 0}, types.Position{X: 0, Y: 2}, nil, true},
+		{"character between", types.Position{X: 0, Y: 0}, types.Position{X: 4, Y: 0}, []types.Position{{X: 2, Y: 0}}, false},
+		{"character on the target", types.Position{X: 0, Y: 2}, types.Position{X: 4, Y: 2}, []types.Position{{X: 4, Y: 2}}, true},
+		{"obstacle beside the line", types.Position{X: 0, Y: 2}, types.Position{X: 4, Y: 2}, nil, true},
+		{"diagonal past the obstacle", types.Position{X: 1, Y: 0}, types.Position{X: 3, Y: 2}, nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			occupied := make(map[types.Position]bool)
+			for _, position := range test.characters {
+				occupied[position] = true
+			}
+			got := HasLineOfSight(test.from, test.to, func(pos types.Position) bool {
+				return occupied[pos] || board.BlocksLineOfSight(pos)
+			})
+			if got != test.want {
+				t.Errorf("HasLineOfSight = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckSpellTargetReasons(t *testing.T) {
+	board := mustBoard(t,
+		"A....",
+		".#...",
+		"....B",
+	)
+	caster := types.Position{X: 0, Y: 0}
+	empty := types.Position{X: 1, Y: 0}
+	spell := types.Spell{Range: 3, NeedsLineOfSight: true}
+
+	tests := []struct {
+		name   string
+		spell  types.Spell
+		target types.Position
+		want   error
+	}{
+		{"in range", spell, types.Position{X: 2, Y: 0}, nil},
+		{"off the board", spell, types.Position{X: -1, Y: 0}, ErrOffBoard},
+		{"obstacle", spell, types.Position{X: 1, Y: 1}, ErrTargetObstacle},
+		{"too far", spell, types.Position{X: 4, Y: 0}, ErrOutOfRange},
+		{"not in line", types.Spell{Range: 3, CastInLineOnly: true}, types.Position{X: 1, Y: 2}, ErrNotInLine},
+		{"empty cell", types.Spell{Range: 3}, empty, ErrTargetNotValid},
+		{"behind the obstacle", spell, types.Position{X: 2, Y: 1}, ErrNoLineOfSight},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckSpellTarget(test.spell, board, caster, test.target,
+				func(pos types.Position) bool { return pos != empty },
+				board.BlocksLineOfSight,
+			)
+			if err != test.want {
+				t.Errorf("CheckSpellTarget = %v, want %v", err, test.want)
+			}
+		})
+	}
+	// Obstacles are refused with a targeting code, not a movement one
+	if code := ErrTargetObstacle.Code; code != types.ReasonInvalidTarget {
+		t.Errorf("obstacle code = %s, want %s", code, types.ReasonInvalidTarget)
+	}
+}
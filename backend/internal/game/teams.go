@@ -0,0 +1,66 @@
+package game
+
+import (
+	"game-server/internal/types"
+	"sort"
+)
+
+var ErrUnknownTeam = &RuleError{Code: types.ReasonUnknownTeam, Message: "unknown team"}
+
+// Teams returns the teams players can join: one per placement group of the map
+func (gm *GameManager) Teams() []string {
+	return gm.board.PlacementGroups()
+}
+
+// IsTeam reports whether players can join the given team
+func (gm *GameManager) IsTeam(team string) bool {
+	for _, candidate := range gm.Teams() {
+		if candidate == team {
+			return true
+		}
+	}
+	return false
+}
+
+// SetFriendlyFire sets whether spells damage the caster's teammates
+func (gm *GameManager) SetFriendlyFire(enabled bool) {
+	gm.mutex.Lock()
+	defer gm.mutex.Unlock()
+	gm.friendlyFire = enabled
+}
+
+// FriendlyFire reports whether spells damage the caster's teammates
+func (gm *GameManager) FriendlyFire() bool {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+	return gm.friendlyFire
+}
+
+// SmallestTeam returns the team with the fewest players, the first one in order on ties
+func SmallestTeam(teams []string, players map[string]types.Player) string {
+	counts := make(map[string]int, len(teams))
+	for _, player := range players {
+		counts[player.Team]++
+	}
+	smallest := ""
+	for _, team := range teams {
+		if smallest == "" || counts[team] < counts[smallest] {
+			smallest = team
+		}
+	}
+	return smallest
+}
+
+// TeamMembers returns the members of a team, ordered by user ID
+func TeamMembers(team string, players map[string]types.Player) []types.TeamMember {
+	members := []types.TeamMember{}
+	for userID, player := range players {
+		if player.Team == team {
+			members = append(members, types.TeamMember{UserID: userID, UserName: player.UserName})
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].UserID < members[j].UserID
+	})
+	return members
+}
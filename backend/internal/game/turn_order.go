@@ -0,0 +1,99 @@
+package game
+
+import (
+	"errors"
+	"game-server/internal/types"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// DefaultInitiative is the initiative given to new characters
+const DefaultInitiative = 100
+
+// ComputeTimeline returns the user IDs of the players in turn order: highest
+// initiative first, ties broken by draws from rng. Players are visited in user
+// ID order so that the same seed always gives the same timeline.
+func ComputeTimeline(players map[string]types.Player, rng *rand.Rand) []string {
+	userIDs := make([]string, 0, len(players))
+	for userID, player := range players {
+		if player.Character != nil {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Strings(userIDs)
+
+	tieBreaks := make(map[string]int64, len(userIDs))
+	for _, userID := range userIDs {
+		tieBreaks[userID] = rng.Int63()
+	}
+
+	sort.SliceStable(userIDs, func(i, j int) bool {
+		a, b := players[userIDs[i]].Character, players[userIDs[j]].Character
+		if a.Initiative != b.Initiative {
+			return a.Initiative > b.Initiative
+		}
+		return tieBreaks[userIDs[i]] < tieBreaks[userIDs[j]]
+	})
+	return userIDs
+}
+
+// StartFight computes the timeline and starts the first turn of the first round.
+// It returns the user ID of the player who plays first.
+func (gm *GameManager) StartFight() (string, error) {
+	gm.mutex.Lock()
+	defer gm.mutex.Unlock()
+
+	timeline := ComputeTimeline(gm.fold.state.Players, gm.rng)
+	if len(timeline) == 0 {
+		return "", errors.New("no character to start the fight")
+	}
+
+	gm.record(&FightStarted{Timeline: timeline, StartedAt: time.Now()})
+	gm.record(&TurnStarted{UserID: timeline[0], TurnIndex: 0, TurnNumber: 1})
+	return timeline[0], nil
+}
+
+// GetCurrentTurnPlayer returns the user ID of the player whose turn it is
+func (gm *GameManager) GetCurrentTurnPlayer() (string, bool) {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	currentState := gm.fold.state
+	if currentState.CurrentTurnIndex >= len(currentState.Timeline) {
+		return "", false
+	}
+	return currentState.Timeline[currentState.CurrentTurnIndex], true
+}
+
+// AdvanceTurn starts the turn of the next living character of the timeline.
+// Wrapping around the timeline starts a new round.
+// It returns the user ID of the player who plays next.
+func (gm *GameManager) AdvanceTurn() (string, error) {
+	gm.mutex.Lock()
+	defer gm.mutex.Unlock()
+
+	currentState := gm.fold.state
+	timeline := currentState.Timeline
+	if len(timeline) == 0 {
+		return "", errors.New("the fight has not started")
+	}
+
+	index := currentState.CurrentTurnIndex
+	turnNumber := currentState.TurnNumber
+	for step := 0; step < len(timeline); step++ {
+		index++
+		if index == len(timeline) {
+			index = 0
+			turnNumber++
+		}
+
+		player, exists := currentState.Players[timeline[index]]
+		if exists && player.Character != nil && player.Character.IsAlive {
+			gm.record(&TurnStarted{UserID: timeline[index], TurnIndex: index, TurnNumber: turnNumber})
+			return timeline[index], nil
+		}
+	}
+
+	return "", errors.New("no living character left in the timeline")
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"game-server/internal/types"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// playersWithInitiative returns a player for each initiative, keyed by user ID
+func playersWithInitiative(initiatives map[string]int) map[string]types.Player {
+	players := make(map[string]types.Player, len(initiatives))
+	for userID, initiative := range initiatives {
+		character := NewCharacter(userID, "red", "X")
+		character.Initiative = initiative
+		players[userID] = types.Player{UserID: userID, Character: character}
+	}
+	return players
+}
+
+func TestComputeTimelineOrdersByInitiative(t *testing.T) {
+	players := playersWithInitiative(map[string]int{"alice": 80, "bob": 120, "carol": 100, "dave": 90})
+	players["spectator"] = types.Player{UserID: "spectator"}
+
+	got := ComputeTimeline(players, rand.New(rand.NewSource(1)))
+	if want := []string{"bob", "carol", "dave", "alice"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ComputeTimeline = %v, want %v", got, want)
+	}
+}
+
+func TestComputeTimelineBreaksTiesWithTheSeed(t *testing.T) {
+	players := playersWithInitiative(map[string]int{"alice": 100, "bob": 100, "carol": 100, "dave": 50})
+
+	orders := make(map[string]bool)
+	for seed := int64(1); seed <= 50; seed++ {
+		timeline := ComputeTimeline(players, rand.New(rand.NewSource(seed)))
+		if again := ComputeTimeline(players, rand.New(rand.NewSource(seed))); !reflect.DeepEqual(timeline, again) {
+			t.Fatalf("seed %d gave %v then %v", seed, timeline, again)
+		}
+		if len(timeline) != 4 || timeline[3] != "dave" {
+			t.Fatalf("seed %d: timeline = %v, want dave last", seed, timeline)
+		}
+		orders[timeline[0]+timeline[1]+timeline[2]] = true
+	}
+	// Three tied players can be ordered in six ways
+	if len(orders) != 6 {
+		t.Errorf("ties were broken in %d ways over 50 seeds, want 6", len(orders))
+	}
+}
+
+func TestAdvanceTurnSkipsDeadCharacters(t *testing.T) {
+	gm := newTestGameManager(t)
+	players := readyPlayers()
+	players["carol"] = types.Player{UserID: "carol", Team: "A", IsReady: true, Character: NewCharacter("Carol", "green", "C")}
+	startTestFight(t, gm, players)
+	timeline := gm.GetCurrentState().Timeline
+
+	// The second character of the timeline dies before its turn
+	gm.mutex.Lock()
+	gm.record(&DamageApplied{SourceID: timeline[0], TargetID: timeline[1], Amount: DefaultHealth})
+	gm.mutex.Unlock()
+
+	tests := []struct {
+		userID     string
+		turnNumber int
+	}{
+		{timeline[2], 1},
+		{timeline[0], 2},
+		{timeline[2], 2},
+	}
+	for _, test := range tests {
+		userID, err := gm.AdvanceTurn()
+		if err != nil {
+			t.Fatalf("AdvanceTurn: %v", err)
+		}
+		if userID != test.userID || gm.GetTurnNumber() != test.turnNumber {
+			t.Errorf("turn %d of %s, want turn %d of %s", gm.GetTurnNumber(), userID, test.turnNumber, test.userID)
+		}
+		if current, _ := gm.GetCurrentTurnPlayer(); current != userID {
+			t.Errorf("current turn player = %s, want %s", current, userID)
+		}
+	}
+}
+
+func TestAdvanceTurnWithoutLivingCharacters(t *testing.T) {
+	gm := newTestGameManager(t)
+	if _, err := gm.AdvanceTurn(); err == nil {
+		t.Error("AdvanceTurn started a turn before the fight")
+	}
+
+	startTestFight(t, gm, readyPlayers())
+	gm.mutex.Lock()
+	for userID := range gm.fold.state.Players {
+		gm.record(&DamageApplied{SourceID: userID, TargetID: userID, Amount: DefaultHealth})
+	}
+	gm.mutex.Unlock()
+	if _, err := gm.AdvanceTurn(); err == nil {
+		t.Error("AdvanceTurn started the turn of a dead character")
+	}
+}
@@ -0,0 +1,94 @@
+package matchmaking
+
+import (
+	"game-server/internal/types"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultRating is the rating of a player's first ranked match
+	DefaultRating = 1500
+	// kFactor is the most points a single match can move a rating
+	kFactor = 32
+)
+
+// NewRating returns the rating of a player who never played a mode
+func NewRating(userID, userName, mode string) types.Rating {
+	return types.Rating{
+		UserID:   userID,
+		UserName: userName,
+		Mode:     mode,
+		Rating:   DefaultRating,
+	}
+}
+
+// ExpectedScore is the Elo probability that a side rated a beats a side rated b
+func ExpectedScore(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// UpdateRatings rates the players of a finished match, given by team. A team is
+// rated as the average of its players against the average of its opponents, and
+// each of its players wins or loses the same points. An empty winning team is a draw.
+// It returns the updated ratings, ordered by user ID.
+func UpdateRatings(teams map[string][]types.Rating, winningTeam string, playedAt time.Time) []types.Rating {
+	averages := make(map[string]float64, len(teams))
+	for team, ratings := range teams {
+		averages[team] = averageRating(ratings)
+	}
+
+	var updated []types.Rating
+	for team, ratings := range teams {
+		var opponents []types.Rating
+		for other, otherRatings := range teams {
+			if other != team {
+				opponents = append(opponents, otherRatings...)
+			}
+		}
+		if len(opponents) == 0 {
+			continue
+		}
+
+		score := 0.5
+		switch {
+		case winningTeam == team:
+			score = 1
+		case winningTeam != "":
+			score = 0
+		}
+		delta := int(math.Round(kFactor * (score - ExpectedScore(averages[team], averageRating(opponents)))))
+
+		for _, rating := range ratings {
+			rating.Rating += delta
+			rating.GamesPlayed++
+			switch score {
+			case 1:
+				rating.Wins++
+			case 0:
+				rating.Losses++
+			default:
+				rating.Draws++
+			}
+			rating.UpdatedAt = playedAt
+			updated = append(updated, rating)
+		}
+	}
+
+	sort.Slice(updated, func(i, j int) bool {
+		return updated[i].UserID < updated[j].UserID
+	})
+	return updated
+}
+
+func averageRating(ratings []types.Rating) float64 {
+	if len(ratings) == 0 {
+		return DefaultRating
+	}
+	total := 0
+	for _, rating := range ratings {
+		total += rating.Rating
+	}
+	return float64(total) / float64(len(ratings))
+}
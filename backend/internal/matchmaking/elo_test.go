@@ -0,0 +1,114 @@
+package matchmaking
+
+import (
+	"game-server/internal/types"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestExpectedScore(t *testing.T) {
+	tests := []struct {
+		a, b float64
+		want float64
+	}{
+		{1500, 1500, 0.5},
+		{1900, 1500, 10.0 / 11},
+		{1500, 1900, 1.0 / 11},
+	}
+	for _, test := range tests {
+		if got := ExpectedScore(test.a, test.b); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("ExpectedScore(%v, %v) = %v, want %v", test.a, test.b, got, test.want)
+		}
+		if sum := ExpectedScore(test.a, test.b) + ExpectedScore(test.b, test.a); math.Abs(sum-1) > 1e-9 {
+			t.Errorf("expected scores of %v and %v sum to %v, want 1", test.a, test.b, sum)
+		}
+	}
+}
+
+func TestUpdateRatings(t *testing.T) {
+	playedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rating := func(userID string, value int) types.Rating {
+		return types.Rating{UserID: userID, Mode: "test", Rating: value}
+	}
+
+	tests := []struct {
+		name        string
+		teams       map[string][]types.Rating
+		winningTeam string
+		want        map[string]int
+	}{
+		{
+			name:        "even 1v1",
+			teams:       map[string][]types.Rating{"A": {rating("alice", 1500)}, "B": {rating("bob", 1500)}},
+			winningTeam: "A",
+			want:        map[string]int{"alice": 1516, "bob": 1484},
+		},
+		{
+			name:        "favourite wins",
+			teams:       map[string][]types.Rating{"A": {rating("alice", 1900)}, "B": {rating("bob", 1500)}},
+			winningTeam: "A",
+			want:        map[string]int{"alice": 1903, "bob": 1497},
+		},
+		{
+			name:        "underdog wins",
+			teams:       map[string][]types.Rating{"A": {rating("alice", 1900)}, "B": {rating("bob", 1500)}},
+			winningTeam: "B",
+			want:        map[string]int{"alice": 1871, "bob": 1529},
+		},
+		{
+			name:        "draw",
+			teams:       map[string][]types.Rating{"A": {rating("alice", 1900)}, "B": {rating("bob", 1500)}},
+			winningTeam: "",
+			want:        map[string]int{"alice": 1887, "bob": 1513},
+		},
+		{
+			// Teams are rated on their averages, 1500 against 1500
+			name: "2v2 on team averages",
+			teams: map[string][]types.Rating{
+				"A": {rating("alice", 1700), rating("carol", 1300)},
+				"B": {rating("bob", 1500), rating("dave", 1500)},
+			},
+			winningTeam: "B",
+			want:        map[string]int{"alice": 1684, "carol": 1284, "bob": 1516, "dave": 1516},
+		},
+		{
+			name:        "no opponents",
+			teams:       map[string][]types.Rating{"A": {rating("alice", 1500)}},
+			winningTeam: "A",
+			want:        map[string]int{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before := make(map[string]int)
+			for _, ratings := range test.teams {
+				for _, rating := range ratings {
+					before[rating.UserID] = rating.Rating
+				}
+			}
+
+			updated := UpdateRatings(test.teams, test.winningTeam, playedAt)
+			if len(updated) != len(test.want) {
+				t.Fatalf("UpdateRatings = %+v, want %d ratings", updated, len(test.want))
+			}
+			// Points are only exchanged between teams of the same size
+			total := 0
+			for i, rating := range updated {
+				if i > 0 && updated[i-1].UserID >= rating.UserID {
+					t.Errorf("ratings are not ordered by user ID: %+v", updated)
+				}
+				if rating.Rating != test.want[rating.UserID] {
+					t.Errorf("%s rated %d, want %d", rating.UserID, rating.Rating, test.want[rating.UserID])
+				}
+				if rating.GamesPlayed != 1 || rating.Wins+rating.Losses+rating.Draws != 1 || !rating.UpdatedAt.Equal(playedAt) {
+					t.Errorf("%s counters = %+v, want one game played at %v", rating.UserID, rating, playedAt)
+				}
+				total += rating.Rating - before[rating.UserID]
+			}
+			if total != 0 {
+				t.Errorf("rating changes sum to %d, want 0", total)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// Package matchmaking groups queued players of similar skill into ranked matches
+// and rates them from the results.
+package matchmaking
+
+import (
+	"game-server/internal/game"
+	"game-server/internal/types"
+	"sort"
+)
+
+// Mode is a kind of ranked match, with the number of players in each of its two teams
+type Mode struct {
+	ID       string `json:"id"`
+	TeamSize int    `json:"teamSize"`
+}
+
+var modes = map[string]Mode{
+	"1v1": {ID: "1v1", TeamSize: 1},
+	"2v2": {ID: "2v2", TeamSize: 2},
+}
+
+var ErrUnknownMode = &game.RuleError{Code: types.ReasonUnknownMode, Message: "unknown matchmaking mode"}
+
+// GetMode returns a mode by ID
+func GetMode(id string) (Mode, bool) {
+	mode, exists := modes[id]
+	return mode, exists
+}
+
+// Modes returns every mode, ordered by team size
+func Modes() []Mode {
+	list := make([]Mode, 0, len(modes))
+	for _, mode := range modes {
+		list = append(list, mode)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].TeamSize < list[j].TeamSize
+	})
+	return list
+}
@@ -0,0 +1,188 @@
+package matchmaking
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// initialRatingSpread is the widest rating gap accepted in a match right after queuing
+	initialRatingSpread = 100
+	// ratingSpreadGrowth widens the accepted gap for each second a player waits
+	ratingSpreadGrowth = 10
+	// maxRatingSpread is the widest rating gap ever accepted
+	maxRatingSpread = 1000
+)
+
+// Entry is a player waiting in the queue of a mode
+type Entry struct {
+	UserID   string
+	UserName string
+	Rating   int
+	JoinedAt time.Time
+}
+
+// Match is a group of queued players split into two teams
+type Match struct {
+	Mode  Mode
+	Teams [2][]Entry
+}
+
+// Queue holds the players waiting for a ranked match, one queue per mode
+type Queue struct {
+	entries map[string][]Entry
+	mutex   sync.Mutex
+}
+
+func NewQueue() *Queue {
+	return &Queue{
+		entries: make(map[string][]Entry),
+	}
+}
+
+// Join puts a player in the queue of a mode, taking it out of any other queue
+func (q *Queue) Join(mode Mode, entry Entry) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.remove(entry.UserID)
+	q.entries[mode.ID] = append(q.entries[mode.ID], entry)
+}
+
+// Requeue puts the players of a match that could not start back in the queue of
+// its mode, where they keep the place their joining time gives them
+func (q *Queue) Requeue(mode Mode, entries []Entry) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, entry := range entries {
+		q.remove(entry.UserID)
+		q.entries[mode.ID] = append(q.entries[mode.ID], entry)
+	}
+	sort.SliceStable(q.entries[mode.ID], func(i, j int) bool {
+		return q.entries[mode.ID][i].JoinedAt.Before(q.entries[mode.ID][j].JoinedAt)
+	})
+}
+
+// Leave takes a player out of the queue. It returns false if the player was not queued.
+func (q *Queue) Leave(userID string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.remove(userID)
+}
+
+// remove takes a player out of every queue. The caller must hold the mutex.
+func (q *Queue) remove(userID string) bool {
+	removed := false
+	for modeID, entries := range q.entries {
+		kept := entries[:0]
+		for _, entry := range entries {
+			if entry.UserID == userID {
+				removed = true
+			} else {
+				kept = append(kept, entry)
+			}
+		}
+		q.entries[modeID] = kept
+	}
+	return removed
+}
+
+// Len returns the number of queued players
+func (q *Queue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	count := 0
+	for _, entries := range q.entries {
+		count += len(entries)
+	}
+	return count
+}
+
+// Match takes every match it can form out of the queues. The players who waited
+// the longest are matched first, with the players whose rating is the closest to
+// theirs. The rating gap accepted in a match widens as they wait.
+func (q *Queue) Match(now time.Time) []Match {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var matches []Match
+	for _, mode := range Modes() {
+		for {
+			match, found := q.matchOne(mode, now)
+			if !found {
+				break
+			}
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// matchOne forms a single match in the queue of a mode, if it can.
+// The caller must hold the mutex.
+func (q *Queue) matchOne(mode Mode, now time.Time) (Match, bool) {
+	entries := q.entries[mode.ID]
+	size := 2 * mode.TeamSize
+	if len(entries) < size {
+		return Match{}, false
+	}
+
+	// Entries are kept in joining order, so the oldest anchor comes first
+	for _, anchor := range entries {
+		spread := ratingSpread(now.Sub(anchor.JoinedAt))
+
+		var candidates []Entry
+		for _, entry := range entries {
+			if entry.UserID != anchor.UserID && abs(entry.Rating-anchor.Rating) <= spread {
+				candidates = append(candidates, entry)
+			}
+		}
+		if len(candidates) < size-1 {
+			continue
+		}
+
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return abs(candidates[i].Rating-anchor.Rating) < abs(candidates[j].Rating-anchor.Rating)
+		})
+		players := append([]Entry{anchor}, candidates[:size-1]...)
+		for _, player := range players {
+			q.remove(player.UserID)
+		}
+		return Match{Mode: mode, Teams: splitTeams(players)}, true
+	}
+	return Match{}, false
+}
+
+// ratingSpread returns the widest rating gap accepted for a player who waited that long
+func ratingSpread(waited time.Duration) int {
+	return min(initialRatingSpread+int(waited/time.Second)*ratingSpreadGrowth, maxRatingSpread)
+}
+
+// splitTeams balances players into two teams by picking them in turn, best rated
+// first, in a snake order: 1-2, 2-1, 1-2...
+func splitTeams(players []Entry) [2][]Entry {
+	sorted := append([]Entry(nil), players...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Rating > sorted[j].Rating
+	})
+
+	var teams [2][]Entry
+	for i, player := range sorted {
+		team := i % 2
+		if (i/2)%2 == 1 {
+			team = 1 - team
+		}
+		teams[team] = append(teams[team], player)
+	}
+	return teams
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
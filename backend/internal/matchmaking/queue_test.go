@@ -0,0 +1,127 @@
+package matchmaking
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRequeueKeepsJoiningOrder(t *testing.T) {
+	q := NewQueue()
+	mode, _ := GetMode("2v2")
+	start := time.Now()
+	q.Join(mode, Entry{UserID: "carol", JoinedAt: start.Add(2 * time.Second)})
+	q.Join(mode, Entry{UserID: "dave", JoinedAt: start.Add(3 * time.Second)})
+
+	q.Requeue(mode, []Entry{{UserID: "alice", JoinedAt: start}, {UserID: "bob", JoinedAt: start.Add(time.Second)}})
+
+	var order []string
+	for _, entry := range q.entries[mode.ID] {
+		order = append(order, entry.UserID)
+	}
+	want := []string{"alice", "bob", "carol", "dave"}
+	if len(order) != len(want) {
+		t.Fatalf("queue = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("queue = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestRatingSpreadWidensWithWaiting(t *testing.T) {
+	tests := []struct {
+		waited time.Duration
+		want   int
+	}{
+		{0, 100},
+		{500 * time.Millisecond, 100},
+		{time.Second, 110},
+		{30 * time.Second, 400},
+		{time.Hour, 1000},
+	}
+	for _, test := range tests {
+		if got := ratingSpread(test.waited); got != test.want {
+			t.Errorf("ratingSpread(%v) = %d, want %d", test.waited, got, test.want)
+		}
+	}
+}
+
+func TestMatchWaitsForTheRatingWindow(t *testing.T) {
+	q := NewQueue()
+	mode, _ := GetMode("1v1")
+	start := time.Now()
+	q.Join(mode, Entry{UserID: "alice", Rating: 1500, JoinedAt: start})
+	q.Join(mode, Entry{UserID: "bob", Rating: 1750, JoinedAt: start})
+
+	tests := []struct {
+		waited  time.Duration
+		matches int
+	}{
+		{0, 0},
+		{14 * time.Second, 0},
+		{15 * time.Second, 1},
+	}
+	for _, test := range tests {
+		if matches := q.Match(start.Add(test.waited)); len(matches) != test.matches {
+			t.Fatalf("%d matches after %v, want %d", len(matches), test.waited, test.matches)
+		}
+	}
+	if q.Len() != 0 {
+		t.Errorf("%d players still queued after their match", q.Len())
+	}
+}
+
+func TestMatchPicksTheClosestRatings(t *testing.T) {
+	q := NewQueue()
+	mode, _ := GetMode("1v1")
+	start := time.Now()
+	q.Join(mode, Entry{UserID: "alice", Rating: 1500, JoinedAt: start})
+	q.Join(mode, Entry{UserID: "bob", Rating: 1580, JoinedAt: start.Add(time.Second)})
+	q.Join(mode, Entry{UserID: "carol", Rating: 1520, JoinedAt: start.Add(2 * time.Second)})
+
+	matches := q.Match(start.Add(2 * time.Second))
+	if len(matches) != 1 {
+		t.Fatalf("%d matches, want 1", len(matches))
+	}
+	teams := matches[0].Teams
+	if teams[0][0].UserID != "carol" || teams[1][0].UserID != "alice" {
+		t.Errorf("match = %+v, want alice against carol", teams)
+	}
+	if q.Len() != 1 || !q.Leave("bob") {
+		t.Error("bob is not left waiting in the queue")
+	}
+}
+
+func TestSplitTeamsBalancesRatings(t *testing.T) {
+	tests := []struct {
+		name    string
+		ratings []int
+		want    [2][]string
+	}{
+		{"1v1", []int{1400, 1600}, [2][]string{{"1600"}, {"1400"}}},
+		{"2v2", []int{1300, 1500, 1600, 1400}, [2][]string{{"1600", "1300"}, {"1500", "1400"}}},
+		{"3v3", []int{1000, 1100, 1200, 1300, 1400, 1500}, [2][]string{{"1500", "1200", "1100"}, {"1400", "1300", "1000"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var players []Entry
+			for _, rating := range test.ratings {
+				players = append(players, Entry{UserID: strconv.Itoa(rating), Rating: rating})
+			}
+
+			teams := splitTeams(players)
+			for i := range teams {
+				var got []string
+				for _, player := range teams[i] {
+					got = append(got, player.UserID)
+				}
+				if !reflect.DeepEqual(got, test.want[i]) {
+					t.Errorf("team %d = %v, want %v", i+1, got, test.want[i])
+				}
+			}
+		})
+	}
+}
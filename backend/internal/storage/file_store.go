@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"game-server/internal/game"
+	"game-server/internal/types"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// FileStore keeps the recorded games, ratings and accounts in memory and writes them to a JSON file
+// after every change. Player stats are rebuilt from the games when the file is loaded.
+// Replays are larger and only read on demand: each one is written to its own file in
+// a replays directory next to the store file.
+type FileStore struct {
+	*MemoryStore
+	path string
+}
+
+// fileContents is the layout of the store file
+type fileContents struct {
+	Games    []types.GameRecord `json:"games"`
+	Ratings  []types.Rating     `json:"ratings,omitempty"`
+	Accounts []types.Account    `json:"accounts,omitempty"`
+}
+
+// OpenFileStore loads the store file at path, starting empty if it does not exist yet
+func OpenFileStore(path string) (*FileStore, error) {
+	store := &FileStore{
+		MemoryStore: NewMemoryStore(),
+		path:        path,
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store file: %w", err)
+	}
+
+	var contents fileContents
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return nil, fmt.Errorf("failed to parse store file: %w", err)
+	}
+	for _, record := range contents.Games {
+		if err := store.MemoryStore.saveGame(record); err != nil {
+			return nil, fmt.Errorf("invalid store file: %w", err)
+		}
+	}
+	store.MemoryStore.saveRatings(contents.Ratings)
+	for _, account := range contents.Accounts {
+		if err := store.MemoryStore.createAccount(account); err != nil {
+			return nil, fmt.Errorf("invalid store file: account %q: %w", account.Name, err)
+		}
+	}
+	return store, nil
+}
+
+// SaveGame records a finished game and writes the store file
+func (s *FileStore) SaveGame(record types.GameRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.MemoryStore.saveGame(record); err != nil {
+		return err
+	}
+	if err := s.write(); err != nil {
+		// Keep memory and file in line
+		s.games = s.games[:len(s.games)-1]
+		s.stats = make(map[string]types.PlayerStats)
+		for _, recorded := range s.games {
+			addToStats(s.stats, recorded)
+		}
+		return err
+	}
+	return nil
+}
+
+// SaveRatings records updated ratings and writes the store file
+func (s *FileStore) SaveRatings(ratings []types.Rating) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous := make(map[string]types.Rating, len(s.ratings))
+	for key, rating := range s.ratings {
+		previous[key] = rating
+	}
+	s.MemoryStore.saveRatings(ratings)
+	if err := s.write(); err != nil {
+		// Keep memory and file in line
+		s.ratings = previous
+		return err
+	}
+	return nil
+}
+
+// CreateAccount records a new account and writes the store file
+func (s *FileStore) CreateAccount(account types.Account) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.MemoryStore.createAccount(account); err != nil {
+		return err
+	}
+	if err := s.write(); err != nil {
+		// Keep memory and file in line
+		delete(s.accounts, accountKey(account.Name))
+		return err
+	}
+	return nil
+}
+
+// SaveReplay writes the replay of a finished game to its own file
+func (s *FileStore) SaveReplay(replay game.Replay) error {
+	if !gameIDPattern.MatchString(replay.GameID) {
+		return fmt.Errorf("invalid game ID %q", replay.GameID)
+	}
+
+	data, err := json.Marshal(replay)
+	if err != nil {
+		return fmt.Errorf("failed to encode replay: %w", err)
+	}
+	return writeFile(s.replayPath(replay.GameID), data)
+}
+
+// GetReplay reads the replay of a recorded game
+func (s *FileStore) GetReplay(gameID string) (game.Replay, bool, error) {
+	if !gameIDPattern.MatchString(gameID) {
+		return game.Replay{}, false, nil
+	}
+
+	data, err := os.ReadFile(s.replayPath(gameID))
+	if errors.Is(err, os.ErrNotExist) {
+		return game.Replay{}, false, nil
+	}
+	if err != nil {
+		return game.Replay{}, false, fmt.Errorf("failed to read replay: %w", err)
+	}
+
+	var replay game.Replay
+	if err := json.Unmarshal(data, &replay); err != nil {
+		return game.Replay{}, false, fmt.Errorf("failed to parse replay: %w", err)
+	}
+	return replay, true, nil
+}
+
+// replayPath returns the file holding the replay of a game
+func (s *FileStore) replayPath(gameID string) string {
+	return filepath.Join(filepath.Dir(s.path), "replays", gameID+".json")
+}
+
+// write replaces the store file with the current games, ratings and accounts.
+// The caller must hold the mutex.
+func (s *FileStore) write() error {
+	ratings := make([]types.Rating, 0, len(s.ratings))
+	for _, rating := range s.ratings {
+		ratings = append(ratings, rating)
+	}
+	sort.Slice(ratings, func(i, j int) bool {
+		return ratingKey(ratings[i].UserID, ratings[i].Mode) < ratingKey(ratings[j].UserID, ratings[j].Mode)
+	})
+
+	accounts := make([]types.Account, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].CreatedAt.Before(accounts[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(fileContents{Games: s.games, Ratings: ratings, Accounts: accounts}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode store: %w", err)
+	}
+	return writeFile(s.path, data)
+}
+
+// writeFile replaces a file of the store. It writes a temporary file first so
+// that a crash never leaves a half written file behind.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create store directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create store file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write store file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write store file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write store file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace store file: %w", err)
+	}
+	return nil
+}
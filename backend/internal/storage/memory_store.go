@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"fmt"
+	"game-server/internal/game"
+	"game-server/internal/types"
+	"sort"
+	"sync"
+)
+
+// MemoryStore keeps everything in memory. It is lost on restart and meant for tests.
+type MemoryStore struct {
+	games    []types.GameRecord
+	replays  map[string]game.Replay
+	stats    map[string]types.PlayerStats
+	ratings  map[string]types.Rating
+	accounts map[string]types.Account
+	mutex    sync.RWMutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		replays:  make(map[string]game.Replay),
+		stats:    make(map[string]types.PlayerStats),
+		ratings:  make(map[string]types.Rating),
+		accounts: make(map[string]types.Account),
+	}
+}
+
+func (s *MemoryStore) SaveGame(record types.GameRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.saveGame(record)
+}
+
+// saveGame records a game. The caller must hold the mutex.
+func (s *MemoryStore) saveGame(record types.GameRecord) error {
+	for _, recorded := range s.games {
+		if recorded.ID == record.ID {
+			return fmt.Errorf("game %s is already recorded", record.ID)
+		}
+	}
+	if !gameIDPattern.MatchString(record.ID) {
+		return fmt.Errorf("invalid game ID %q", record.ID)
+	}
+	s.games = append(s.games, record)
+	addToStats(s.stats, record)
+	return nil
+}
+
+func (s *MemoryStore) ListGames() ([]types.GameRecord, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	games := make([]types.GameRecord, len(s.games))
+	copy(games, s.games)
+	sort.SliceStable(games, func(i, j int) bool {
+		return games[i].FinishedAt.After(games[j].FinishedAt)
+	})
+	return games, nil
+}
+
+func (s *MemoryStore) GetGame(id string) (types.GameRecord, bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, recorded := range s.games {
+		if recorded.ID == id {
+			return recorded, true, nil
+		}
+	}
+	return types.GameRecord{}, false, nil
+}
+
+func (s *MemoryStore) SaveReplay(replay game.Replay) error {
+	if !gameIDPattern.MatchString(replay.GameID) {
+		return fmt.Errorf("invalid game ID %q", replay.GameID)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.replays[replay.GameID] = replay
+	return nil
+}
+
+func (s *MemoryStore) GetReplay(gameID string) (game.Replay, bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	replay, exists := s.replays[gameID]
+	return replay, exists, nil
+}
+
+func (s *MemoryStore) GetPlayerStats(userID string) (types.PlayerStats, bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stats, exists := s.stats[userID]
+	return stats, exists, nil
+}
+
+func (s *MemoryStore) ListPlayerStats() ([]types.PlayerStats, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stats := make([]types.PlayerStats, 0, len(s.stats))
+	for _, playerStats := range s.stats {
+		stats = append(stats, playerStats)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].UserID < stats[j].UserID
+	})
+	return stats, nil
+}
+
+func (s *MemoryStore) GetRating(userID string, mode string) (types.Rating, bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	rating, exists := s.ratings[ratingKey(userID, mode)]
+	return rating, exists, nil
+}
+
+func (s *MemoryStore) SaveRatings(ratings []types.Rating) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.saveRatings(ratings)
+	return nil
+}
+
+// saveRatings records ratings. The caller must hold the mutex.
+func (s *MemoryStore) saveRatings(ratings []types.Rating) {
+	for _, rating := range ratings {
+		s.ratings[ratingKey(rating.UserID, rating.Mode)] = rating
+	}
+}
+
+func (s *MemoryStore) ListRatings(mode string) ([]types.Rating, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var ratings []types.Rating
+	for _, rating := range s.ratings {
+		if rating.Mode == mode {
+			ratings = append(ratings, rating)
+		}
+	}
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		return ratings[i].UserID < ratings[j].UserID
+	})
+	return ratings, nil
+}
+
+func (s *MemoryStore) CreateAccount(account types.Account) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.createAccount(account)
+}
+
+// createAccount records an account. The caller must hold the mutex.
+func (s *MemoryStore) createAccount(account types.Account) error {
+	key := accountKey(account.Name)
+	if _, exists := s.accounts[key]; exists {
+		return ErrAccountExists
+	}
+	s.accounts[key] = account
+	return nil
+}
+
+func (s *MemoryStore) GetAccount(name string) (types.Account, bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	account, exists := s.accounts[accountKey(name)]
+	return account, exists, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,90 @@
+// Package storage keeps finished games, their replays, player statistics,
+// ranked ratings and user accounts across restarts.
+package storage
+
+import (
+	"errors"
+	"game-server/internal/game"
+	"game-server/internal/types"
+	"regexp"
+	"strings"
+)
+
+// Store records finished games, their replays, the statistics of their players,
+// the ratings of ranked players and user accounts
+type Store interface {
+	// SaveGame records a finished game and adds it to the stats of its players
+	SaveGame(record types.GameRecord) error
+	// ListGames returns the recorded games, most recent first
+	ListGames() ([]types.GameRecord, error)
+	// GetGame returns a recorded game by ID
+	GetGame(id string) (types.GameRecord, bool, error)
+	// SaveReplay records the replay of a finished game
+	SaveReplay(replay game.Replay) error
+	// GetReplay returns the replay of a recorded game by game ID
+	GetReplay(gameID string) (game.Replay, bool, error)
+	// GetPlayerStats returns the stats of a player
+	GetPlayerStats(userID string) (types.PlayerStats, bool, error)
+	// ListPlayerStats returns the stats of every player, ordered by user ID
+	ListPlayerStats() ([]types.PlayerStats, error)
+	// GetRating returns the rating of a player in a ranked mode
+	GetRating(userID string, mode string) (types.Rating, bool, error)
+	// SaveRatings records updated ratings
+	SaveRatings(ratings []types.Rating) error
+	// ListRatings returns the ratings of a ranked mode, best first
+	ListRatings(mode string) ([]types.Rating, error)
+	// CreateAccount records a new account. It fails with ErrAccountExists if the
+	// name is taken, whatever its case.
+	CreateAccount(account types.Account) error
+	// GetAccount returns an account by name, whatever its case
+	GetAccount(name string) (types.Account, bool, error)
+	// Close releases the resources of the store
+	Close() error
+}
+
+// ErrAccountExists is returned when an account is created with a name already taken
+var ErrAccountExists = errors.New("account already exists")
+
+// gameIDPattern matches the game IDs the store accepts, which are also file names
+var gameIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ratingKey identifies the rating of a player in a mode
+func ratingKey(userID, mode string) string {
+	return mode + "/" + userID
+}
+
+// accountKey identifies an account by name, so that names differing only in case
+// are the same account
+func accountKey(name string) string {
+	return strings.ToLower(name)
+}
+
+// addToStats adds the result of a game to the stats of its human players
+func addToStats(stats map[string]types.PlayerStats, record types.GameRecord) {
+	for _, participant := range record.Participants {
+		if participant.IsBot {
+			continue
+		}
+		playerStats := stats[participant.UserID]
+		playerStats.UserID = participant.UserID
+		playerStats.UserName = participant.UserName
+		playerStats.GamesPlayed++
+		if participant.Won {
+			playerStats.Wins++
+		} else {
+			playerStats.Losses++
+		}
+		playerStats.DamageDealt += participant.DamageDealt
+		playerStats.DamageTaken += participant.DamageTaken
+		playerStats.Kills += participant.Kills
+		if record.FinishedAt.After(playerStats.LastPlayedAt) {
+			playerStats.LastPlayedAt = record.FinishedAt
+		}
+		stats[participant.UserID] = playerStats
+	}
+}
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*FileStore)(nil)
+)
@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"errors"
+	"game-server/internal/game"
+	"game-server/internal/types"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// stores opens each store implementation, so that they all pass the same tests
+var stores = map[string]func(t *testing.T) Store{
+	"memory": func(t *testing.T) Store {
+		return NewMemoryStore()
+	},
+	"file": func(t *testing.T) Store {
+		store, err := OpenFileStore(filepath.Join(t.TempDir(), "store.json"))
+		if err != nil {
+			t.Fatalf("OpenFileStore: %v", err)
+		}
+		return store
+	},
+}
+
+// testStart is the start time of the recorded test games
+var testStart = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// testGame returns a finished 1v1 game between alice and bob, with a bot on alice's team
+func testGame(id string, finishedAt time.Time, winner string) types.GameRecord {
+	return types.GameRecord{
+		ID:          id,
+		RoomID:      "room",
+		MapID:       "arena",
+		StartedAt:   finishedAt.Add(-5 * time.Minute),
+		FinishedAt:  finishedAt,
+		Turns:       6,
+		WinningTeam: map[string]string{"alice": "A", "bob": "B"}[winner],
+		Participants: []types.Participant{
+			{UserID: "alice", UserName: "Alice", Team: "A", Won: winner == "alice", FightStats: types.FightStats{DamageDealt: 30, DamageTaken: 10, Kills: 1}},
+			{UserID: "bob", UserName: "Bob", Team: "B", Won: winner == "bob", FightStats: types.FightStats{DamageDealt: 10, DamageTaken: 30}},
+			{UserID: "bot-1", UserName: "Bot 1 (easy)", Team: "A", IsBot: true, Won: winner == "alice", FightStats: types.FightStats{DamageDealt: 5}},
+		},
+	}
+}
+
+// testReplay returns the replay of a game, with a single event
+func testReplay(gameID string) game.Replay {
+	return game.Replay{
+		Version:      1,
+		GameID:       gameID,
+		Seed:         42,
+		InitialState: &types.GameState{MessageType: "game_state", GameStatus: game.PhasePlacement},
+		Events: []game.EventRecord{
+			{Sequence: 1, Time: testStart, Event: &game.TurnStarted{}},
+		},
+	}
+}
+
+func TestStoreGamesAndStats(t *testing.T) {
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+
+			if err := store.SaveGame(testGame("first", testStart, "alice")); err != nil {
+				t.Fatalf("SaveGame: %v", err)
+			}
+			if err := store.SaveGame(testGame("second", testStart.Add(time.Hour), "bob")); err != nil {
+				t.Fatalf("SaveGame: %v", err)
+			}
+			if err := store.SaveGame(testGame("first", testStart, "alice")); err == nil {
+				t.Error("SaveGame accepted a game recorded twice")
+			}
+			if err := store.SaveGame(testGame("../escape", testStart, "alice")); err == nil {
+				t.Error("SaveGame accepted an invalid game ID")
+			}
+
+			games, err := store.ListGames()
+			if err != nil {
+				t.Fatalf("ListGames: %v", err)
+			}
+			if len(games) != 2 || games[0].ID != "second" || games[1].ID != "first" {
+				t.Fatalf("ListGames = %v, want second then first", gameIDs(games))
+			}
+			record, exists, err := store.GetGame("first")
+			if err != nil || !exists || record.WinningTeam != "A" || len(record.Participants) != 3 {
+				t.Errorf("GetGame(first) = %+v, %v, %v", record, exists, err)
+			}
+			if _, exists, _ := store.GetGame("missing"); exists {
+				t.Error("GetGame found a game never recorded")
+			}
+
+			// Bots have no stats, and each player has one win and one loss
+			stats, err := store.ListPlayerStats()
+			if err != nil {
+				t.Fatalf("ListPlayerStats: %v", err)
+			}
+			if len(stats) != 2 || stats[0].UserID != "alice" || stats[1].UserID != "bob" {
+				t.Fatalf("ListPlayerStats = %+v, want alice and bob", stats)
+			}
+			alice, exists, err := store.GetPlayerStats("alice")
+			if err != nil || !exists {
+				t.Fatalf("GetPlayerStats(alice) = %v, %v", exists, err)
+			}
+			want := types.PlayerStats{
+				UserID:       "alice",
+				UserName:     "Alice",
+				GamesPlayed:  2,
+				Wins:         1,
+				Losses:       1,
+				LastPlayedAt: testStart.Add(time.Hour),
+				FightStats:   types.FightStats{DamageDealt: 60, DamageTaken: 20, Kills: 2},
+			}
+			if !alice.LastPlayedAt.Equal(want.LastPlayedAt) {
+				t.Errorf("alice last played at %v, want %v", alice.LastPlayedAt, want.LastPlayedAt)
+			}
+			alice.LastPlayedAt = want.LastPlayedAt
+			if alice != want {
+				t.Errorf("GetPlayerStats(alice) = %+v, want %+v", alice, want)
+			}
+			if _, exists, _ := store.GetPlayerStats("bot-1"); exists {
+				t.Error("a bot got player stats")
+			}
+		})
+	}
+}
+
+func TestStoreReplays(t *testing.T) {
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+
+			if err := store.SaveReplay(testReplay("first")); err != nil {
+				t.Fatalf("SaveReplay: %v", err)
+			}
+			if err := store.SaveReplay(testReplay("../escape")); err == nil {
+				t.Error("SaveReplay accepted an invalid game ID")
+			}
+
+			replay, exists, err := store.GetReplay("first")
+			if err != nil || !exists {
+				t.Fatalf("GetReplay(first) = %v, %v", exists, err)
+			}
+			if replay.GameID != "first" || replay.Seed != 42 || replay.InitialState.GameStatus != game.PhasePlacement {
+				t.Errorf("GetReplay(first) = %+v", replay)
+			}
+			if len(replay.Events) != 1 || replay.Events[0].Event.EventType() != "turn_started" {
+				t.Errorf("replay events = %+v, want one turn_started", replay.Events)
+			}
+			for _, gameID := range []string{"missing", "../escape"} {
+				if _, exists, err := store.GetReplay(gameID); exists || err != nil {
+					t.Errorf("GetReplay(%q) = %v, %v, want not found", gameID, exists, err)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreRatings(t *testing.T) {
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+
+			err := store.SaveRatings([]types.Rating{
+				{UserID: "alice", Mode: "1v1", Rating: 1500},
+				{UserID: "bob", Mode: "1v1", Rating: 1520},
+				{UserID: "carol", Mode: "1v1", Rating: 1500},
+				{UserID: "alice", Mode: "2v2", Rating: 1400},
+			})
+			if err != nil {
+				t.Fatalf("SaveRatings: %v", err)
+			}
+			// A later save replaces the rating of the player in that mode only
+			if err := store.SaveRatings([]types.Rating{{UserID: "alice", Mode: "1v1", Rating: 1480, GamesPlayed: 1, Losses: 1}}); err != nil {
+				t.Fatalf("SaveRatings: %v", err)
+			}
+
+			rating, exists, err := store.GetRating("alice", "1v1")
+			if err != nil || !exists || rating.Rating != 1480 || rating.Losses != 1 {
+				t.Errorf("GetRating(alice, 1v1) = %+v, %v, %v", rating, exists, err)
+			}
+			rating, exists, err = store.GetRating("alice", "2v2")
+			if err != nil || !exists || rating.Rating != 1400 {
+				t.Errorf("GetRating(alice, 2v2) = %+v, %v, %v", rating, exists, err)
+			}
+			if _, exists, _ := store.GetRating("bob", "2v2"); exists {
+				t.Error("GetRating found a rating never recorded")
+			}
+
+			// Best first, ties by user ID
+			ratings, err := store.ListRatings("1v1")
+			if err != nil {
+				t.Fatalf("ListRatings: %v", err)
+			}
+			var order []string
+			for _, rating := range ratings {
+				order = append(order, rating.UserID)
+			}
+			want := []string{"bob", "carol", "alice"}
+			if len(order) != len(want) || order[0] != want[0] || order[1] != want[1] || order[2] != want[2] {
+				t.Errorf("ListRatings(1v1) = %v, want %v", order, want)
+			}
+		})
+	}
+}
+
+func TestStoreAccounts(t *testing.T) {
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+
+			account := types.Account{ID: "user-1", Name: "Alice", PasswordHash: "hash", CreatedAt: testStart}
+			if err := store.CreateAccount(account); err != nil {
+				t.Fatalf("CreateAccount: %v", err)
+			}
+			err := store.CreateAccount(types.Account{ID: "user-2", Name: "ALICE", PasswordHash: "other", CreatedAt: testStart})
+			if !errors.Is(err, ErrAccountExists) {
+				t.Errorf("CreateAccount(ALICE) = %v, want ErrAccountExists", err)
+			}
+
+			found, exists, err := store.GetAccount("alice")
+			if err != nil || !exists || found.ID != "user-1" || found.Name != "Alice" || found.PasswordHash != "hash" {
+				t.Errorf("GetAccount(alice) = %+v, %v, %v", found, exists, err)
+			}
+			if _, exists, _ := store.GetAccount("bob"); exists {
+				t.Error("GetAccount found an account never created")
+			}
+		})
+	}
+}
+
+func TestFileStoreReloadsAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore: %v", err)
+	}
+	if err := store.SaveGame(testGame("first", testStart, "alice")); err != nil {
+		t.Fatalf("SaveGame: %v", err)
+	}
+	if err := store.SaveReplay(testReplay("first")); err != nil {
+		t.Fatalf("SaveReplay: %v", err)
+	}
+	if err := store.SaveRatings([]types.Rating{{UserID: "alice", Mode: "1v1", Rating: 1516}}); err != nil {
+		t.Fatalf("SaveRatings: %v", err)
+	}
+	if err := store.CreateAccount(types.Account{ID: "user-1", Name: "Alice", PasswordHash: "hash", CreatedAt: testStart}); err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	store.Close()
+
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore after restart: %v", err)
+	}
+	defer reopened.Close()
+
+	if record, exists, _ := reopened.GetGame("first"); !exists || record.WinningTeam != "A" {
+		t.Errorf("game after restart = %+v, %v", record, exists)
+	}
+	// Stats are rebuilt from the games
+	if stats, exists, _ := reopened.GetPlayerStats("alice"); !exists || stats.Wins != 1 || stats.DamageDealt != 30 {
+		t.Errorf("alice's stats after restart = %+v, %v", stats, exists)
+	}
+	if replay, exists, _ := reopened.GetReplay("first"); !exists || replay.Seed != 42 || len(replay.Events) != 1 {
+		t.Errorf("replay after restart = %+v, %v", replay, exists)
+	}
+	if rating, exists, _ := reopened.GetRating("alice", "1v1"); !exists || rating.Rating != 1516 {
+		t.Errorf("rating after restart = %+v, %v", rating, exists)
+	}
+	if account, exists, _ := reopened.GetAccount("ALICE"); !exists || account.ID != "user-1" {
+		t.Errorf("account after restart = %+v, %v", account, exists)
+	}
+	// Accounts keep their names unique after a restart
+	if err := reopened.CreateAccount(types.Account{ID: "user-2", Name: "alice"}); !errors.Is(err, ErrAccountExists) {
+		t.Errorf("CreateAccount(alice) after restart = %v, want ErrAccountExists", err)
+	}
+}
+
+func gameIDs(games []types.GameRecord) []string {
+	ids := make([]string, len(games))
+	for i, record := range games {
+		ids[i] = record.ID
+	}
+	return ids
+}
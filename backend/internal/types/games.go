@@ -17,55 +17,109 @@ type Character struct {
 	HasPlayedThisTurn bool        `json:"hasPlayedThisTurn"`
 	Health            int         `json:"health"`
 	IsAlive           bool        `json:"isAlive"`
+	Initiative        int         `json:"initiative"`
+	// Remaining turns before each spell can be cast again, keyed by spell ID
+	SpellCooldowns map[string]int `json:"spellCooldowns,omitempty"`
+	// Number of casts of each spell during the current turn, keyed by spell ID
+	SpellCastsThisTurn map[string]int `json:"spellCastsThisTurn,omitempty"`
+	// Status effects currently attached to the character
+	Effects []StatusEffect `json:"effects,omitempty"`
+}
+
+// StatusEffect is an effect lasting a number of the carrier's turns, such as poison
+type StatusEffect struct {
+	Kind           string `json:"kind"`
+	Value          int    `json:"value"`
+	RemainingTurns int    `json:"remainingTurns"`
+	StackPolicy    string `json:"stackPolicy,omitempty"`
+	SourceUserID   string `json:"sourceUserId"`
+	SpellID        int    `json:"spellId"`
+}
+
+// SpellEffect describes a status effect a spell attaches to every character it hits
+type SpellEffect struct {
+	Kind        string `json:"kind"`
+	Value       int    `json:"value"`
+	Duration    int    `json:"duration"`
+	StackPolicy string `json:"stackPolicy,omitempty"`
 }
 
 type Player struct {
 	UserID        string     `json:"userId"`
 	UserName      string     `json:"userName"`
+	Team          string     `json:"team"`
 	Character     *Character `json:"character"`
 	Status        string     `json:"status"`
 	IsCurrentTurn bool       `json:"isCurrentTurn"`
 	IsReady       bool       `json:"isReady"`
 	HasPositioned bool       `json:"hasPositioned"`
+	// Bots are played by the server and have no websocket
+	IsBot         bool   `json:"isBot,omitempty"`
+	BotDifficulty string `json:"botDifficulty,omitempty"`
 }
 
 type GameState struct {
-	MessageType string            `json:"type"`
-	Players     map[string]Player `json:"players"`
-	TurnNumber  int               `json:"turnNumber"`
-	GameStatus  string            `json:"status"`
-	Spells      map[string]Spell  `json:"spells"`
+	MessageType      string            `json:"type"`
+	Players          map[string]Player `json:"players"`
+	TurnNumber       int               `json:"turnNumber"`
+	GameStatus       string            `json:"status"`
+	Spells           map[string]Spell  `json:"spells"`
+	Timeline         []string          `json:"timeline"`
+	CurrentTurnIndex int               `json:"currentTurnIndex"`
+	Map              *BoardMap         `json:"map,omitempty"`
+	FriendlyFire     bool              `json:"friendlyFire"`
+	// Deadline of the current turn as a Unix time in milliseconds. Clients count
+	// down from it, so that the state does not change every millisecond.
+	TurnEndsAt    int64            `json:"turnEndsAt,omitempty"`
+	UsingTimeBank bool             `json:"usingTimeBank,omitempty"`
+	TimeBanksMs   map[string]int64 `json:"timeBanksMs,omitempty"`
+}
+
+// BoardMap is a board as authored in a map file and sent to clients.
+// Each row is a line of cells starting at Origin: '.' is a floor cell, '#' an
+// obstacle, 'o' a hole, an upper case letter a floor cell where the characters
+// of that team are placed and ' ' no cell at all.
+type BoardMap struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Origin Position `json:"origin"`
+	Rows   []string `json:"rows"`
 }
 
 type Spell struct {
-	ID               int    `json:"id"`
-	Name             string `json:"name"`
-	BgColor          string `json:"bgColor"`
-	BorderColor      string `json:"borderColor"`
-	Icon             string `json:"icon"`
-	APCost           int    `json:"APCost"`
-	Range            int    `json:"range"`
-	NeedsLineOfSight bool   `json:"needsLineOfSight"`
-	MaxCastsPerTurn  int    `json:"maxCastsPerTurn"`
-	Damage           int    `json:"damage"`
-	AreaOfEffect     string `json:"areaOfEffect"`
-	Type             string `json:"type"`
-	Description      string `json:"description,omitempty"`
-	CriticalChance   int    `json:"criticalChance,omitempty"`
-	CriticalDamage   int    `json:"criticalDamage,omitempty"`
-	CastInLineOnly   bool   `json:"castInLineOnly,omitempty"`
-	CastOnEmptyCell  bool   `json:"castOnEmptyCell,omitempty"`
-	Cooldown         int    `json:"cooldown,omitempty"`
-	IsWeapon         bool   `json:"isWeapon,omitempty"`
+	ID               int           `json:"id"`
+	Name             string        `json:"name"`
+	BgColor          string        `json:"bgColor"`
+	BorderColor      string        `json:"borderColor"`
+	Icon             string        `json:"icon"`
+	APCost           int           `json:"APCost"`
+	Range            int           `json:"range"`
+	NeedsLineOfSight bool          `json:"needsLineOfSight"`
+	MaxCastsPerTurn  int           `json:"maxCastsPerTurn"`
+	Damage           int           `json:"damage"`
+	AreaOfEffect     string        `json:"areaOfEffect"`
+	Type             string        `json:"type"`
+	Description      string        `json:"description,omitempty"`
+	CriticalChance   int           `json:"criticalChance,omitempty"`
+	CriticalDamage   int           `json:"criticalDamage,omitempty"`
+	CastInLineOnly   bool          `json:"castInLineOnly,omitempty"`
+	CastOnEmptyCell  bool          `json:"castOnEmptyCell,omitempty"`
+	Cooldown         int           `json:"cooldown,omitempty"`
+	IsWeapon         bool          `json:"isWeapon,omitempty"`
+	Effects          []SpellEffect `json:"effects,omitempty"`
 }
 
-type GameHistory struct {
-	GameHistory map[string]GameState `json:"gameHistory"`
+// SpellHit is the damage a spell dealt to one character
+type SpellHit struct {
+	UserID   string   `json:"userId"`
+	Position Position `json:"position"`
+	Damage   int      `json:"damage"`
+	Critical bool     `json:"critical"`
+	IsDead   bool     `json:"isDead"`
 }
 
-const (
-	GameStatusHasNotStarted = "not_started"
-	GameStatusWaiting       = "waiting"
-	GameStatusPlaying       = "playing"
-	GameStatusFinished      = "finished"
-)
\ No newline at end of file
+// ReachableCell is a cell a character can walk to, with the movement points it costs
+type ReachableCell struct {
+	Position
+	Cost int `json:"cost"`
+}
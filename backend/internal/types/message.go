@@ -67,7 +67,255 @@ type CharacterPositionedMessage struct {
 	UserID   string   `json:"userId"`
 }
 
+// The combat messages below are broadcast before the game state an action leads
+// to, so that clients can animate and log the action without diffing states.
+
+// SpellCastMessage describes a cast: the cells its area covered and its outcome, hit by hit
+type SpellCastMessage struct {
+	Type           string     `json:"type"`
+	CasterID       string     `json:"casterId"`
+	SpellID        int        `json:"spellId"`
+	TargetPosition Position   `json:"targetPosition"`
+	AffectedCells  []Position `json:"affectedCells"`
+	Critical       bool       `json:"critical"`
+	Hits           []SpellHit `json:"hits"`
+}
+
+// CharacterMovedMessage describes a move, cell by cell from the starting cell
+type CharacterMovedMessage struct {
+	Type   string     `json:"type"`
+	UserID string     `json:"userId"`
+	From   Position   `json:"from"`
+	Path   []Position `json:"path"`
+}
+
+// CharacterDiedMessage tells that a character died, hit by a spell or by a status effect
+type CharacterDiedMessage struct {
+	Type     string `json:"type"`
+	UserID   string `json:"userId"`
+	KillerID string `json:"killerId,omitempty"`
+	// Spell of the killing hit, 0 when a status effect killed the character
+	SpellID int `json:"spellId,omitempty"`
+}
+
+// TurnStartedMessage tells whose turn it is
+type TurnStartedMessage struct {
+	Type       string `json:"type"`
+	UserID     string `json:"userId"`
+	TurnNumber int    `json:"turnNumber"`
+	// When the turn times out, in Unix milliseconds; 0 when turns are not timed
+	TurnEndsAt int64 `json:"turnEndsAt,omitempty"`
+}
+
 type GameOverMessage struct {
+	Type        string       `json:"type"`
+	WinningTeam string       `json:"winningTeam"`
+	Members     []TeamMember `json:"members"`
+}
+
+// TeamMember identifies a player of a team
+type TeamMember struct {
+	UserID   string `json:"userId"`
+	UserName string `json:"userName"`
+}
+
+type JoinTeamMessage struct {
+	BaseMessage
+	Team string `json:"team"`
+}
+
+type AddBotMessage struct {
+	BaseMessage
+	Difficulty string `json:"difficulty"`
+	// Team of the bot, the smallest team when omitted
+	Team string `json:"team,omitempty"`
+}
+
+type RemoveBotMessage struct {
+	BaseMessage
+	BotID string `json:"botId"`
+}
+
+type SpellCatalogueMessage struct {
+	Type   string  `json:"type"`
+	Spells []Spell `json:"spells"`
+}
+
+type RoomMessage struct {
+	BaseMessage
+	RoomID string `json:"roomId,omitempty"`
+	Name   string `json:"name,omitempty"`
+	MapID  string `json:"mapId,omitempty"`
+	// Whether spells damage teammates in a new room, true when omitted
+	FriendlyFire *bool `json:"friendlyFire,omitempty"`
+	// Turn time limit and time bank of a new room, the server defaults when omitted
+	TurnSeconds     int `json:"turnSeconds,omitempty"`
+	TimeBankSeconds int `json:"timeBankSeconds,omitempty"`
+	// How late spectators of a new room receive its broadcasts, no delay when omitted
+	SpectatorDelaySeconds int `json:"spectatorDelaySeconds,omitempty"`
+	// Join or create the room as a spectator instead of a player
+	Spectator bool `json:"spectator,omitempty"`
+}
+
+// SpellTargetsRequest asks for the cells a spell can be cast on. With a target,
+// it also asks what a cast on that cell would hit.
+type SpellTargetsRequest struct {
+	BaseMessage
+	SpellID int       `json:"spellId"`
+	Target  *Position `json:"target,omitempty"`
+}
+
+// ReachableCellsMessage answers a get_reachable_cells request with the cells the
+// requesting player's character can walk to
+type ReachableCellsMessage struct {
+	Type      string          `json:"type"`
+	MessageID string          `json:"messageId"`
+	Cells     []ReachableCell `json:"cells"`
+}
+
+// SpellTargetsMessage answers a get_spell_targets request. The affected cells and
+// hits are only set for a requested target that is one of the valid targets.
+type SpellTargetsMessage struct {
+	Type          string     `json:"type"`
+	MessageID     string     `json:"messageId"`
+	SpellID       int        `json:"spellId"`
+	Targets       []Position `json:"targets"`
+	Target        *Position  `json:"target,omitempty"`
+	AffectedCells []Position `json:"affectedCells,omitempty"`
+	Hits          []SpellHit `json:"hits,omitempty"`
+}
+
+// SyncModeMessage switches a client between full game_state messages and state_patch messages
+type SyncModeMessage struct {
+	BaseMessage
+	Patches bool `json:"patches"`
+}
+
+// StatePatchMessage carries the changes of a room's game state as a JSON merge
+// patch (RFC 7396), to apply on the state of version BaseVersion. A client whose
+// state is of another version asks for a snapshot with a sync_request message.
+// As merge patches remove null values, fields that are null in a full state are
+// missing from a patched one.
+type StatePatchMessage struct {
+	Type        string      `json:"type"`
+	Version     int64       `json:"version"`
+	BaseVersion int64       `json:"baseVersion"`
+	Patch       interface{} `json:"patch"`
+}
+
+type WatchReplayMessage struct {
+	BaseMessage
+	GameID string `json:"gameId"`
+	// Playback speed, 1 plays the fight at the pace it was played
+	Speed float64 `json:"speed,omitempty"`
+}
+
+type ReplayFinishedMessage struct {
+	Type   string `json:"type"`
+	GameID string `json:"gameId"`
+}
+
+// QueueMessage joins or leaves the matchmaking queue of a mode, or asks for its leaderboard
+type QueueMessage struct {
+	BaseMessage
+	Mode string `json:"mode,omitempty"`
+}
+
+type MatchFoundMessage struct {
 	Type   string `json:"type"`
-	Winner string `json:"winner"`
+	Mode   string `json:"mode"`
+	RoomID string `json:"roomId"`
+	Team   string `json:"team"`
+}
+
+type LeaderboardMessage struct {
+	Type    string   `json:"type"`
+	Mode    string   `json:"mode"`
+	Ratings []Rating `json:"ratings"`
+}
+
+type RoomInfo struct {
+	ID                    string `json:"id"`
+	Name                  string `json:"name"`
+	PlayerCount           int    `json:"playerCount"`
+	ClientCount           int    `json:"clientCount"`
+	SpectatorCount        int    `json:"spectatorCount"`
+	GameStatus            string `json:"status"`
+	MapID                 string `json:"mapId"`
+	FriendlyFire          bool   `json:"friendlyFire"`
+	TurnSeconds           int    `json:"turnSeconds"`
+	TimeBankSeconds       int    `json:"timeBankSeconds"`
+	SpectatorDelaySeconds int    `json:"spectatorDelaySeconds"`
+	// Matchmaking mode of a ranked room, empty for other rooms
+	Mode string `json:"mode,omitempty"`
+}
+
+type RoomListMessage struct {
+	Type  string     `json:"type"`
+	Rooms []RoomInfo `json:"rooms"`
+}
+
+type RoomJoinedMessage struct {
+	Type      string   `json:"type"`
+	Room      RoomInfo `json:"room"`
+	Spectator bool     `json:"spectator"`
+}
+
+// Reason codes carried by action_result and error messages
+const (
+	ReasonNotYourTurn       = "NOT_YOUR_TURN"
+	ReasonNotEnoughAP       = "NOT_ENOUGH_AP"
+	ReasonNotEnoughMP       = "NOT_ENOUGH_MP"
+	ReasonOutOfRange        = "OUT_OF_RANGE"
+	ReasonUnknownSpell      = "UNKNOWN_SPELL"
+	ReasonSpellOnCooldown   = "SPELL_ON_COOLDOWN"
+	ReasonMaxCastsReached   = "MAX_CASTS_REACHED"
+	ReasonNoLineOfSight     = "NO_LINE_OF_SIGHT"
+	ReasonNotInLine         = "NOT_IN_LINE"
+	ReasonInvalidTarget     = "INVALID_TARGET"
+	ReasonOffBoard          = "OFF_BOARD"
+	ReasonCellOccupied      = "CELL_OCCUPIED"
+	ReasonNoPath            = "NO_PATH"
+	ReasonNotWalkable       = "NOT_WALKABLE"
+	ReasonInvalidPlacement  = "INVALID_PLACEMENT"
+	ReasonPlayerNotFound    = "PLAYER_NOT_FOUND"
+	ReasonNoPosition        = "NO_POSITION"
+	ReasonCharacterDead     = "CHARACTER_DEAD"
+	ReasonUnknownRoom       = "UNKNOWN_ROOM"
+	ReasonUnknownMap        = "UNKNOWN_MAP"
+	ReasonUnknownTeam       = "UNKNOWN_TEAM"
+	ReasonUnknownDifficulty = "UNKNOWN_DIFFICULTY"
+	ReasonUnknownReplay     = "UNKNOWN_REPLAY"
+	ReasonUnknownMode       = "UNKNOWN_MODE"
+	ReasonRankedRoom        = "RANKED_ROOM"
+	ReasonAccountRequired   = "ACCOUNT_REQUIRED"
+	ReasonInFight           = "IN_FIGHT"
+	ReasonNotEnoughPlayers  = "NOT_ENOUGH_PLAYERS"
+	ReasonPlayersNotReady   = "PLAYERS_NOT_READY"
+	ReasonSingleTeam        = "SINGLE_TEAM"
+	ReasonNotInRoom         = "NOT_IN_ROOM"
+	ReasonSpectator         = "SPECTATOR"
+	ReasonWrongPhase        = "WRONG_PHASE"
+	ReasonInvalidMessage    = "INVALID_MESSAGE"
+	ReasonUnknownMessage    = "UNKNOWN_MESSAGE_TYPE"
+	ReasonIdentityMismatch  = "IDENTITY_MISMATCH"
+	ReasonInternalError     = "INTERNAL_ERROR"
+)
+
+// ActionResultMessage tells the client that sent an action whether it was applied
+type ActionResultMessage struct {
+	Type      string `json:"type"`
+	MessageID string `json:"messageId"`
+	Action    string `json:"action"`
+	Success   bool   `json:"success"`
+	Code      string `json:"code,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// ErrorMessage tells a client that one of its messages could not be processed at all
+type ErrorMessage struct {
+	Type      string `json:"type"`
+	MessageID string `json:"messageId,omitempty"`
+	Code      string `json:"code"`
+	Reason    string `json:"reason"`
 }
@@ -0,0 +1,58 @@
+package types
+
+import "time"
+
+// FightStats are the damage and kills of a player during a fight
+type FightStats struct {
+	DamageDealt int `json:"damageDealt"`
+	DamageTaken int `json:"damageTaken"`
+	Kills       int `json:"kills"`
+}
+
+// GameRecord is the stored result of a finished game
+type GameRecord struct {
+	ID           string        `json:"id"`
+	RoomID       string        `json:"roomId"`
+	MapID        string        `json:"mapId"`
+	StartedAt    time.Time     `json:"startedAt"`
+	FinishedAt   time.Time     `json:"finishedAt"`
+	Turns        int           `json:"turns"`
+	WinningTeam  string        `json:"winningTeam"`
+	Participants []Participant `json:"participants"`
+}
+
+// Participant is a player of a recorded game
+type Participant struct {
+	UserID        string `json:"userId"`
+	UserName      string `json:"userName"`
+	Team          string `json:"team"`
+	IsBot         bool   `json:"isBot,omitempty"`
+	CharacterName string `json:"characterName"`
+	Won           bool   `json:"won"`
+	Survived      bool   `json:"survived"`
+	FightStats
+}
+
+// Rating is the skill rating of a player in a ranked matchmaking mode
+type Rating struct {
+	UserID      string    `json:"userId"`
+	UserName    string    `json:"userName"`
+	Mode        string    `json:"mode"`
+	Rating      int       `json:"rating"`
+	GamesPlayed int       `json:"gamesPlayed"`
+	Wins        int       `json:"wins"`
+	Losses      int       `json:"losses"`
+	Draws       int       `json:"draws"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// PlayerStats are the totals of a player over every recorded game
+type PlayerStats struct {
+	UserID       string    `json:"userId"`
+	UserName     string    `json:"userName"`
+	GamesPlayed  int       `json:"gamesPlayed"`
+	Wins         int       `json:"wins"`
+	Losses       int       `json:"losses"`
+	LastPlayedAt time.Time `json:"lastPlayedAt"`
+	FightStats
+}
@@ -1,6 +1,32 @@
 package types
 
+import "time"
+
 type User struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
+	// Whether the user plays without an account, under a name given by the server
+	Guest bool `json:"guest"`
+}
+
+// Account is a registered user. The password is only kept as a salted hash.
+type Account struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	PasswordHash string    `json:"passwordHash"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Credentials are the name and password sent to register or log in
+type Credentials struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// AuthResponse is sent back on registration and login. The token is passed as
+// the "token" query parameter of /ws to connect as the account's user.
+type AuthResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	User      User      `json:"user"`
 }
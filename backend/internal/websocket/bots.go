@@ -0,0 +1,128 @@
+package websocket
+
+import (
+	"fmt"
+	"game-server/internal/game"
+	"game-server/internal/types"
+	"log"
+	"time"
+)
+
+const (
+	// botThinkDelay is the pause before each bot action, so that players can follow the bot
+	botThinkDelay = 800 * time.Millisecond
+	// maxBotTurnActions caps the actions of a bot turn, in case its plans keep failing
+	maxBotTurnActions = 20
+	botColor          = "#808080"
+)
+
+// addBot adds a bot player to the room's lobby, ready to fight.
+// The bot joins the smallest team when team is empty.
+func (r *Room) addBot(difficulty string, team string) (types.Player, error) {
+	// Bots would skew the ratings of ranked players
+	if r.mode != "" {
+		return types.Player{}, errRankedRoom
+	}
+	if !game.IsBotDifficulty(difficulty) {
+		return types.Player{}, game.ErrUnknownDifficulty
+	}
+	if team == "" {
+		team = game.SmallestTeam(r.gameManager.Teams(), r.playerManager.GetPlayers())
+	} else if !r.gameManager.IsTeam(team) {
+		return types.Player{}, game.ErrUnknownTeam
+	}
+
+	r.botCount++
+	name := fmt.Sprintf("Bot %d", r.botCount)
+	bot := types.Player{
+		UserID:        "bot-" + generateUniqueID(),
+		UserName:      fmt.Sprintf("%s (%s)", name, difficulty),
+		Team:          team,
+		Status:        "waiting-room",
+		IsReady:       true,
+		IsBot:         true,
+		BotDifficulty: difficulty,
+		Character:     game.NewCharacter(name, botColor, "B"),
+	}
+	r.playerManager.UpdatePlayer(bot.UserID, bot)
+	log.Printf("[Game] Added bot %s (%s) to team %s of room %s", bot.UserID, difficulty, team, r.ID)
+	return bot, nil
+}
+
+// removeBot removes a bot player from the room's lobby
+func (r *Room) removeBot(botID string) error {
+	player, exists := r.playerManager.GetPlayer(botID)
+	if !exists || !player.IsBot {
+		return game.ErrPlayerNotFound
+	}
+	r.playerManager.RemovePlayer(botID)
+	return nil
+}
+
+// placeBots places every bot on the first free cell offered to it
+func (r *Room) placeBots() {
+	for userID, player := range r.gameManager.GetCurrentState().Players {
+		if !player.IsBot || player.Character == nil {
+			continue
+		}
+		for _, position := range player.Character.InitialPositions {
+			if position == nil {
+				continue
+			}
+			if err := r.gameManager.SetChosenInitialPosition(userID, *position); err == nil {
+				break
+			}
+		}
+	}
+}
+
+// scheduleBotStep plays the next action of a bot after a short delay
+func (r *Room) scheduleBotStep(botID string) {
+	r.after(botThinkDelay, func() {
+		r.playBotStep(botID)
+	})
+}
+
+// playBotStep plays one action of a bot's turn and schedules the next one,
+// until the bot ends its turn
+func (r *Room) playBotStep(botID string) {
+	// The fight may have ended or moved on since the step was scheduled
+	if r.gameManager.GetStatus() != game.PhaseFighting {
+		return
+	}
+	if currentUserID, ok := r.gameManager.GetCurrentTurnPlayer(); !ok || currentUserID != botID {
+		return
+	}
+	bot, exists := r.playerManager.GetPlayer(botID)
+	if !exists || !bot.IsBot {
+		return
+	}
+
+	action := game.BotAction{Kind: game.BotEndTurn}
+	if r.botTurnActions < maxBotTurnActions {
+		action = r.gameManager.PlanBotAction(botID, bot.BotDifficulty, r.botRng)
+	}
+	r.botTurnActions++
+
+	var err error
+	switch action.Kind {
+	case game.BotMove:
+		err = r.moveCharacter(botID, action.Target)
+	case game.BotCastSpell:
+		err = r.castSpell(botID, action.SpellID, action.Target)
+	default:
+		if err := r.endTurn(botID); err != nil {
+			log.Printf("[Error] Bot %s failed to end its turn: %v", botID, err)
+		}
+		return
+	}
+
+	if err != nil {
+		log.Printf("[Warning] Bot %s failed to %s: %v", botID, action.Kind, err)
+		if err := r.endTurn(botID); err != nil {
+			log.Printf("[Error] Bot %s failed to end its turn: %v", botID, err)
+		}
+		return
+	}
+	r.scheduleBotStep(botID)
+}
@@ -4,19 +4,35 @@ package websocket
 import (
 	"bytes"
 	"encoding/json"
+	"game-server/internal/game"
 	"game-server/internal/types"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
 type Client struct {
-	ID   string
-	Conn *websocket.Conn
-	Send chan []byte
-	Hub  *Hub
-	User *types.User
+	ID      string
+	Conn    *websocket.Conn
+	Send    chan []byte
+	Hub     *Hub
+	User    *types.User
+	Room    *Room
+	Session *Session
+
+	sendMutex sync.Mutex
+	closed    bool
+
+	// Replay the client is watching, only used from the hub goroutine. The room's
+	// broadcasts, which run on the hub goroutine too, skip the client meanwhile.
+	replay *replayStream
+
+	// Whether the client receives state patches, and the last game state it was
+	// sent. Guarded by the mutex of the client's room.
+	patchSync   bool
+	syncedState *stateSnapshot
 }
 
 const (
@@ -26,6 +42,13 @@ const (
 	maxMessageSize = 512                 // Maximum message size allowed from peer.
 )
 
+// InboundMessage is a message read from a client's connection, tagged with the
+// client that sent it so that handlers never have to trust the identity in the payload.
+type InboundMessage struct {
+	Client  *Client
+	Payload []byte
+}
+
 // ReadPump pumps messages from the websocket connection to the hub.
 //
 // The application runs readPump in a per-connection goroutine. The application
@@ -59,7 +82,7 @@ func (c *Client) ReadPump() {
 		} else {
 			log.Printf("[Debug] Received JSON message from client %s:\n%s", c.ID, prettyJSON.String())
 		}
-		c.Hub.Broadcast <- message
+		c.Hub.Inbound <- InboundMessage{Client: c, Payload: message}
 	}
 }
 
@@ -105,3 +128,70 @@ func (c *Client) WritePump() {
 		}
 	}
 }
+
+// closeSend closes the send channel, which makes WritePump close the connection.
+// It is safe to call more than once.
+func (c *Client) closeSend() {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.Send)
+	}
+}
+
+// trySend queues a message without blocking. It returns false if the client
+// is closed or its send buffer is full.
+func (c *Client) trySend(message []byte) bool {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+	if c.closed {
+		return false
+	}
+
+	select {
+	case c.Send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// sendMessage marshals a message and queues it for this client only
+func (c *Client) sendMessage(message interface{}) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[Error] Failed to marshal message for client %s: %v", c.ID, err)
+		return
+	}
+
+	if !c.trySend(data) {
+		log.Printf("[Error] Failed to send to client %s", c.ID)
+	}
+}
+
+// sendActionResult tells the client whether the action of one of its messages was
+// applied. A nil err acknowledges the action, anything else rejects it.
+func (c *Client) sendActionResult(messageID string, action string, err error) {
+	result := types.ActionResultMessage{
+		Type:      "action_result",
+		MessageID: messageID,
+		Action:    action,
+		Success:   err == nil,
+	}
+	if err != nil {
+		result.Code = game.ReasonCode(err)
+		result.Reason = err.Error()
+	}
+	c.sendMessage(result)
+}
+
+// sendError tells the client that one of its messages could not be processed
+func (c *Client) sendError(messageID string, code string, reason string) {
+	c.sendMessage(types.ErrorMessage{
+		Type:      "error",
+		MessageID: messageID,
+		Code:      code,
+		Reason:    reason,
+	})
+}
@@ -4,8 +4,13 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"game-server/internal/auth"
+	"game-server/internal/game"
+	"game-server/internal/matchmaking"
 	"game-server/internal/types"
 	"log"
+	"net"
 	"net/http"
 	"time"
 
@@ -22,43 +27,68 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// maxCredentialsSize caps the body of register and login requests
+const maxCredentialsSize = 4096
+
 func generateUniqueID() string {
 	bytes := make([]byte, 8)
 	rand.Read(bytes)
 	return hex.EncodeToString(bytes)
 }
 
-var activeSessions = make(map[string]bool) // Structure pour stocker les sessions actives
-
-// HandleWebSocket upgrades HTTP connections to WebSocket connections
+// HandleWebSocket upgrades HTTP connections to WebSocket connections.
+// A client can pass the token it received in its user_init message as the
+// "session" query parameter to get its previous identity back. A client that
+// logged in passes its account token as the "token" query parameter to play as
+// the account's user; other clients play as guests.
 func (h *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	var account *types.User
+	if token := r.URL.Query().Get("token"); token != "" {
+		user, err := h.accounts.Authenticate(token)
+		if err != nil {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		account = &user
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("[Error] Upgrading connection: %v", err)
 		return
 	}
-	id := generateUniqueID()
 
-	// Vérifier si l'utilisateur est déjà connecté
-	if activeSessions[id] {
-		log.Printf("[Info] User %s already initialized, skipping init message.", id)
-		return
+	// Resume the session if the token is still valid and belongs to the same user,
+	// otherwise start a new one
+	session, resumed := h.sessions.Resume(r.URL.Query().Get("session"))
+	if resumed && account != nil && session.User.ID != account.ID {
+		resumed = false
 	}
-	// Enregistrer la session active
-	activeSessions[id] = true
-
-	initUser := &types.User{
-		ID:   id,
-		Name: "Guest-" + id[len(id)-6:],
+	if resumed {
+		log.Printf("[Info] User %s resumed its session", session.User.Name)
+	} else if account != nil {
+		session = h.sessions.Create(account)
+		log.Printf("[Info] User %s logged in", account.Name)
+	} else {
+		id := generateUniqueID()
+		session = h.sessions.Create(&types.User{
+			ID:    id,
+			Name:  "Guest-" + id[len(id)-6:],
+			Guest: true,
+		})
 	}
+	initUser := session.User
+	id := initUser.ID
 
 	// Send initialization message
 	initMsg, err := json.Marshal(map[string]interface{}{
-		"type":       "user_init",
-		"messageId":  "init-" + id,
-		"Timestamp":  time.Now(),
-		"user":       initUser,
-		"gameStatus": "creating_player",
+		"type":         "user_init",
+		"messageId":    "init-" + id,
+		"Timestamp":    time.Now(),
+		"user":         initUser,
+		"gameStatus":   game.PhaseLobby,
+		"sessionToken": session.Token,
+		"resumed":      resumed,
 	})
 	if err != nil {
 		log.Printf("[Error] Marshaling init message: %v", err)
@@ -72,18 +102,211 @@ func (h *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Send the spell catalogue so the client does not rely on its own copy
+	catalogueMsg, err := json.Marshal(types.SpellCatalogueMessage{
+		Type:   "spells_catalogue",
+		Spells: h.spells.List(),
+	})
+	if err != nil {
+		log.Printf("[Error] Marshaling spell catalogue message: %v", err)
+		conn.Close()
+		return
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, catalogueMsg); err != nil {
+		log.Printf("[Error] Sending spell catalogue message: %v", err)
+		conn.Close()
+		return
+	}
+
 	client := &Client{
-		ID:   id,
-		Conn: conn,
-		Send: make(chan []byte, 256),
-		Hub:  h,
-		User: initUser,
+		ID:      id,
+		Conn:    conn,
+		Send:    make(chan []byte, 256),
+		Hub:     h,
+		User:    initUser,
+		Session: session,
 	}
 
-	log.Printf("[New Connection] Client %s (%s)", id, "Guest-"+id[len(id)-6:])
+	log.Printf("[New Connection] Client %s (%s)", id, initUser.Name)
 
 	h.Register <- client
 
 	go client.WritePump()
 	go client.ReadPump()
 }
+
+// HandleRegister creates an account from the name and password in the request
+// body and responds with a token for it
+func (h *Hub) HandleRegister(w http.ResponseWriter, r *http.Request) {
+	credentials, ok := readCredentials(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.accounts.Register(credentials)
+	switch {
+	case errors.Is(err, auth.ErrInvalidName), errors.Is(err, auth.ErrInvalidPassword):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, auth.ErrNameTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("[Error] Registering account: %v", err)
+		http.Error(w, "failed to register", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("[Info] Account %s registered", response.User.Name)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, response)
+}
+
+// HandleLogin checks the name and password in the request body and responds
+// with a token for the account
+func (h *Hub) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	credentials, ok := readCredentials(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.accounts.Login(credentials, clientAddress(r))
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if errors.Is(err, auth.ErrTooManyAttempts) {
+		log.Printf("[Warning] Refused login to %s from %s: too many failures", credentials.Name, clientAddress(r))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		log.Printf("[Error] Logging in: %v", err)
+		http.Error(w, "failed to log in", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, response)
+}
+
+// clientAddress returns the IP address a request comes from
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// readCredentials decodes the credentials of a register or login request,
+// answering the request itself if they cannot be read
+func readCredentials(w http.ResponseWriter, r *http.Request) (types.Credentials, bool) {
+	var credentials types.Credentials
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCredentialsSize)).Decode(&credentials); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return types.Credentials{}, false
+	}
+	return credentials, true
+}
+
+// HandleListGames serves the recorded games, most recent first
+func (h *Hub) HandleListGames(w http.ResponseWriter, r *http.Request) {
+	games, err := h.store.ListGames()
+	if err != nil {
+		log.Printf("[Error] Listing games: %v", err)
+		http.Error(w, "failed to list games", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, games)
+}
+
+// HandleGetGame serves a recorded game
+func (h *Hub) HandleGetGame(w http.ResponseWriter, r *http.Request) {
+	record, exists, err := h.store.GetGame(r.PathValue("id"))
+	if err != nil {
+		log.Printf("[Error] Reading game: %v", err)
+		http.Error(w, "failed to read game", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "game not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, record)
+}
+
+// HandleGetReplay serves the replay of a recorded game as a file download
+func (h *Hub) HandleGetReplay(w http.ResponseWriter, r *http.Request) {
+	gameID := r.PathValue("id")
+	replay, exists, err := h.store.GetReplay(gameID)
+	if err != nil {
+		log.Printf("[Error] Reading replay: %v", err)
+		http.Error(w, "failed to read replay", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "replay not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="replay-`+replay.GameID+`.json"`)
+	writeJSON(w, replay)
+}
+
+// HandleListPlayerStats serves the stats of every player
+func (h *Hub) HandleListPlayerStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.store.ListPlayerStats()
+	if err != nil {
+		log.Printf("[Error] Listing player stats: %v", err)
+		http.Error(w, "failed to list player stats", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, stats)
+}
+
+// HandleGetPlayerStats serves the stats of a player
+func (h *Hub) HandleGetPlayerStats(w http.ResponseWriter, r *http.Request) {
+	stats, exists, err := h.store.GetPlayerStats(r.PathValue("userId"))
+	if err != nil {
+		log.Printf("[Error] Reading player stats: %v", err)
+		http.Error(w, "failed to read player stats", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "player not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, stats)
+}
+
+// HandleLeaderboard serves the ratings of a ranked mode, given by the "mode"
+// query parameter, best first
+func (h *Hub) HandleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	ratings, err := h.leaderboard(r.URL.Query().Get("mode"))
+	if errors.Is(err, matchmaking.ErrUnknownMode) {
+		http.Error(w, "unknown mode", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("[Error] Listing ratings: %v", err)
+		http.Error(w, "failed to list ratings", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, ratings)
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[Error] Encoding response: %v", err)
+	}
+}
+
+// HandleSpellCatalogue serves the spell catalogue as JSON
+func (h *Hub) HandleSpellCatalogue(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.spells.List()); err != nil {
+		log.Printf("[Error] Encoding spell catalogue: %v", err)
+	}
+}
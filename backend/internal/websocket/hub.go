@@ -1,128 +1,423 @@
 package websocket
 
 import (
-	"bytes"
 	"encoding/json"
-	"fmt"
+	"game-server/internal/auth"
 	"game-server/internal/game"
+	"game-server/internal/matchmaking"
+	"game-server/internal/storage"
 	"game-server/internal/types"
 	"log"
+	"sort"
 	"sync"
+	"time"
 )
 
+// defaultRoomID is the room every new client joins, so that a client that knows
+// nothing about rooms still ends up in a playable game.
+const defaultRoomID = "main"
+
+// cleanupInterval is how often the hub forgets expired sessions and looks for
+// abandoned rooms
+const cleanupInterval = time.Minute
+
 type Hub struct {
 	// Client management
 	Clients    map[*Client]bool
 	Register   chan *Client
 	Unregister chan *Client
-	Broadcast  chan []byte
+	Inbound    chan InboundMessage
+
+	// Work scheduled by the rooms, such as bot turns, run on the hub goroutine
+	Tasks chan func()
+
+	// Game rooms
+	rooms  map[string]*Room
+	spells *game.SpellCatalogue
+	maps   *game.MapCatalogue
+
+	// Finished games and player stats
+	store storage.Store
+
+	// Resumable sessions
+	sessions *SessionStore
+
+	// User accounts, which authenticate connections
+	accounts *auth.Accounts
 
-	// Game state
-	playerManager *game.PlayerManager
-	gameManager   *game.GameManager
+	// Ranked matchmaking, and whether the queue is due to be matched again
+	queue                *matchmaking.Queue
+	matchmakingScheduled bool
 
 	// Concurrency control
 	mutex sync.Mutex
 }
 
-func NewHub() *Hub {
+func NewHub(spells *game.SpellCatalogue, maps *game.MapCatalogue, store storage.Store, accounts *auth.Accounts) *Hub {
+	tasks := make(chan func())
 	return &Hub{
 		// Initialize channels
-		Broadcast:  make(chan []byte),
+		Inbound:    make(chan InboundMessage),
 		Register:   make(chan *Client),
 		Unregister: make(chan *Client),
+		Tasks:      tasks,
 
 		// Initialize maps
 		Clients: make(map[*Client]bool),
+		rooms: map[string]*Room{
+			defaultRoomID: newDefaultRoom(spells, maps, store, tasks),
+		},
 
-		playerManager: game.NewPlayerManager(),
-		gameManager:   game.NewGameManager(),
+		spells:   spells,
+		maps:     maps,
+		store:    store,
+		sessions: NewSessionStore(),
+		accounts: accounts,
+		queue:    matchmaking.NewQueue(),
 	}
 }
 
-func (h *Hub) BroadcastGameState() error {
+// newDefaultRoom returns a fresh default room, played on the default map
+func newDefaultRoom(spells *game.SpellCatalogue, maps *game.MapCatalogue, store storage.Store, tasks chan func()) *Room {
+	room := NewRoom(defaultRoomID, "Main room", spells, maps.Default(), tasks)
+	room.store = store
+	return room
+}
 
-	state := types.GameState{
-		MessageType: "game_state",
-		Players:     h.playerManager.GetPlayers(),
-		TurnNumber:  h.gameManager.GetTurnNumber(),
-		GameStatus:  h.gameManager.GetStatus(),
-	}
+// RoomSettings are the rules a room is created with
+type RoomSettings struct {
+	// Map of the room, the default map when empty
+	MapID        string
+	FriendlyFire bool
+	// Turn time limit and time bank, the defaults when zero
+	TurnDuration time.Duration
+	TimeBank     time.Duration
+	// How late spectators receive the room's broadcasts, none when zero
+	SpectatorDelay time.Duration
+}
 
-	stateMsg, err := json.Marshal(map[string]interface{}{
-		"type":  "game_state",
-		"state": state,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to marshal game state: %w", err)
+// CreateRoom creates a new empty room with its own game
+func (h *Hub) CreateRoom(name string, settings RoomSettings) (*Room, error) {
+	board := h.maps.Default()
+	if settings.MapID != "" {
+		var exists bool
+		if board, exists = h.maps.Get(settings.MapID); !exists {
+			return nil, game.ErrUnknownMap
+		}
+	}
+	if settings.TurnDuration <= 0 {
+		settings.TurnDuration = defaultTurnDuration
+	}
+	if settings.TimeBank <= 0 {
+		settings.TimeBank = defaultTimeBank
 	}
 
-	h.broadcastMessage(stateMsg)
-	return nil
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	id := generateUniqueID()
+	if name == "" {
+		name = "Room-" + id[len(id)-6:]
+	}
+	room := NewRoom(id, name, h.spells, board, h.Tasks)
+	room.gameManager.SetFriendlyFire(settings.FriendlyFire)
+	room.turnTimer = newTurnTimer(settings.TurnDuration, settings.TimeBank)
+	room.store = h.store
+	room.spectatorDelay = max(settings.SpectatorDelay, 0)
+	h.rooms[id] = room
+	log.Printf("[Room] Created room %s (%s) on map %s", id, name, board.Layout().ID)
+	return room, nil
 }
 
-func (h *Hub) broadcastMessage(message []byte) {
+// GetRoom returns the room with the given ID
+func (h *Hub) GetRoom(roomID string) (*Room, bool) {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
+	room, ok := h.rooms[roomID]
+	return room, ok
+}
+
+// ListRooms returns the summary of every room, ordered by name
+func (h *Hub) ListRooms() []types.RoomInfo {
+	h.mutex.Lock()
+	rooms := make([]*Room, 0, len(h.rooms))
+	for _, room := range h.rooms {
+		rooms = append(rooms, room)
+	}
+	h.mutex.Unlock()
+
+	infos := make([]types.RoomInfo, 0, len(rooms))
+	for _, room := range rooms {
+		infos = append(infos, room.Info())
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
 
-	var prettyJSON bytes.Buffer
-	if err := json.Indent(&prettyJSON, message, "", "  "); err != nil {
-		log.Printf("[Debug] Broadcasting message (raw): %s", string(message))
+// JoinRoom moves a client into a room as a player or a spectator, leaving its
+// current room first. A client already in the room only changes its role.
+func (h *Hub) JoinRoom(client *Client, room *Room, spectator bool) {
+	if client.Room != room {
+		h.LeaveRoom(client)
+	}
+	room.addClient(client, spectator)
+	if client.Session != nil {
+		h.sessions.SetRoom(client.Session, room.ID, spectator)
+	}
+	if spectator {
+		log.Printf("[Room] User %s watches room %s", client.User.Name, room.ID)
+	} else {
+		log.Printf("[Room] User %s joined room %s", client.User.Name, room.ID)
+	}
+}
+
+// LeaveRoom removes a client from its current room. The client's session still
+// remembers the room until the client joins another one or leaves explicitly.
+// The room is cleaned up if nobody can play in it any more.
+func (h *Hub) LeaveRoom(client *Client) {
+	room := client.Room
+	if room == nil {
+		return
+	}
+	room.removeClient(client)
+	log.Printf("[Room] User %s left room %s", client.User.Name, room.ID)
+
+	h.cleanUpRoom(room)
+}
+
+// isAbandoned reports whether nobody can play in a room any more: no client is
+// left in it, and either its game is over or none of its human players has a
+// session that can resume into it
+func (h *Hub) isAbandoned(room *Room) bool {
+	if !room.IsEmpty() {
+		return false
+	}
+	if room.gameManager.GetStatus() == game.PhaseFinished {
+		return true
+	}
+	for userID, player := range room.playerManager.GetPlayers() {
+		if !player.IsBot && h.sessions.InRoom(userID, room.ID) {
+			return false
+		}
+	}
+	return true
+}
+
+// cleanUpRoom drops a room once it is abandoned, stopping its turn countdown and
+// its bots. The default room is replaced by a fresh one instead, unless it is
+// still an untouched lobby.
+func (h *Hub) cleanUpRoom(room *Room) {
+	if !h.isAbandoned(room) {
+		return
+	}
+	if room.ID == defaultRoomID && room.gameManager.GetStatus() == game.PhaseLobby && len(room.playerManager.GetPlayers()) == 0 {
+		return
+	}
+
+	h.mutex.Lock()
+	if h.rooms[room.ID] != room {
+		h.mutex.Unlock()
+		return
+	}
+	if room.ID == defaultRoomID {
+		h.rooms[room.ID] = newDefaultRoom(h.spells, h.maps, h.store, h.Tasks)
 	} else {
-		log.Printf("[Debug] Broadcasting message:\n%s", prettyJSON.String())
+		delete(h.rooms, room.ID)
 	}
-	// Store message in game history through game manager
-	if err := h.gameManager.AddToHistory(message); err != nil {
-		log.Printf("[Error] Failed to add message to history: %v", err)
+	h.mutex.Unlock()
+
+	room.close()
+	log.Printf("[Room] Cleaned up abandoned room %s", room.ID)
+}
+
+// cleanUp forgets the expired sessions and cleans up every abandoned room, such
+// as the room of a fight whose players all left for good, then runs again later
+func (h *Hub) cleanUp() {
+	if swept := h.sessions.Sweep(time.Now()); swept > 0 {
+		log.Printf("[Info] Forgot %d expired sessions", swept)
 	}
 
+	h.mutex.Lock()
+	rooms := make([]*Room, 0, len(h.rooms))
+	for _, room := range h.rooms {
+		rooms = append(rooms, room)
+	}
+	h.mutex.Unlock()
+
+	for _, room := range rooms {
+		h.cleanUpRoom(room)
+	}
+	h.after(cleanupInterval, h.cleanUp)
+}
+
+// fightingRoom returns the room where a user's character is alive in a running game
+func (h *Hub) fightingRoom(userID string) (*Room, bool) {
+	h.mutex.Lock()
+	rooms := make([]*Room, 0, len(h.rooms))
+	for _, room := range h.rooms {
+		rooms = append(rooms, room)
+	}
+	h.mutex.Unlock()
+
+	for _, room := range rooms {
+		if room.isFighting(userID) {
+			return room, true
+		}
+	}
+	return nil, false
+}
+
+// clientByUserID finds the connected client of a user
+func (h *Hub) clientByUserID(userID string) *Client {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
 	for client := range h.Clients {
-		select {
-		case client.Send <- message:
-			log.Printf("[Debug] Sent message to client %s", client.ID)
-		default:
-			close(client.Send)
-			delete(h.Clients, client)
-			log.Printf("[Error] Failed to send to client %s", client.ID)
+		if client.User.ID == userID {
+			return client
+		}
+	}
+	return nil
+}
+
+// register adds a new client to the hub. A client resuming a session replaces any
+// connection still open for the same user, goes back to the session's room and
+// receives that room's recent history and current state. A user without a
+// character in the room's running game can only watch it.
+func (h *Hub) register(client *Client) {
+	if previous := h.clientByUserID(client.User.ID); previous != nil {
+		log.Printf("[Info] Closing previous connection of user %s", client.User.Name)
+		// The room is not cleaned up, the new connection is about to resume into it
+		if room := previous.Room; room != nil {
+			room.removeClient(previous)
+		}
+		h.mutex.Lock()
+		delete(h.Clients, previous)
+		h.mutex.Unlock()
+		previous.closeSend()
+	}
+
+	h.mutex.Lock()
+	h.Clients[client] = true
+	log.Printf("[New Connection] User-%s joined. Total clients: %d", client.ID, len(h.Clients))
+	room := h.rooms[defaultRoomID]
+	roomID, spectator := h.sessions.GetRoom(client.Session)
+	resumedRoom, resumed := h.rooms[roomID]
+	if resumed {
+		room = resumedRoom
+	} else {
+		spectator = false
+	}
+	h.mutex.Unlock()
+
+	spectator = spectator || room.mustSpectate(client.User.ID)
+	h.JoinRoom(client, room, spectator)
+
+	if resumed {
+		if err := room.sendResumeState(client); err != nil {
+			log.Printf("[Error] Failed to send resume state: %v", err)
+		}
+	} else if spectator && room.spectatorDelay > 0 {
+		if err := room.sendSnapshot(client); err != nil {
+			log.Printf("[Error] Failed to send game state: %v", err)
 		}
 	}
 }
 
+// after runs task on the hub goroutine once delay has passed
+func (h *Hub) after(delay time.Duration, task func()) *time.Timer {
+	return time.AfterFunc(delay, func() {
+		h.Tasks <- task
+	})
+}
+
 func (h *Hub) Run() {
+	h.after(cleanupInterval, h.cleanUp)
 	for {
 		select {
 		case client := <-h.Register:
-			h.mutex.Lock()
-			h.Clients[client] = true
-			log.Printf("[New Connection] User-%s joined. Total clients: %d", client.ID, len(h.Clients))
-			h.mutex.Unlock()
+			h.register(client)
 
 		case client := <-h.Unregister:
+			// The session keeps its room so that the user can resume into it
+			h.LeaveRoom(client)
+			client.replay = nil
+			if client.Session != nil {
+				h.sessions.Touch(client.Session)
+			}
 			h.mutex.Lock()
 			if _, ok := h.Clients[client]; ok {
 				delete(h.Clients, client)
-				close(client.Send)
+				client.closeSend()
 			}
 			log.Printf("[Disconnection] User %s left. Total clients: %d", client.User.Name, len(h.Clients))
 			h.mutex.Unlock()
 
-		case message := <-h.Broadcast:
-			log.Printf("[Debug] Received broadcast message: %s", string(message))
-
-			// 1. Désérialiser uniquement le type
-			var baseMsg types.BaseMessage
-			if err := json.Unmarshal(message, &baseMsg); err != nil {
-				log.Printf("[Error] Failed to parse message type: %v", err)
-				continue
+			// A user who reconnected keeps its place in the queue
+			if h.clientByUserID(client.User.ID) == nil {
+				h.queue.Leave(client.User.ID)
 			}
 
-			// 2. Vérifier si un handler existe
-			if handler, exists := messageHandlers[baseMsg.Type]; exists {
-				handler(h, message) // Appeler dynamiquement la fonction
-			} else {
-				log.Printf("[Warning] Unrecognized message type: %s", baseMsg.Type)
-			}
+		case task := <-h.Tasks:
+			task()
+
+		case inbound := <-h.Inbound:
+			h.dispatch(inbound.Client, inbound.Payload)
+		}
+	}
+}
+
+// dispatch checks an inbound message against the sending client and the phase of
+// its room, then hands it to the handler of its type
+func (h *Hub) dispatch(client *Client, message []byte) {
+	log.Printf("[Debug] Received message from client %s: %s", client.ID, string(message))
+
+	// 1. Désérialiser uniquement le type
+	var baseMsg types.BaseMessage
+	if err := json.Unmarshal(message, &baseMsg); err != nil {
+		log.Printf("[Error] Failed to parse message type: %v", err)
+		client.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	// 2. Vérifier si un handler existe
+	handler, exists := messageHandlers[baseMsg.Type]
+	if !exists {
+		log.Printf("[Warning] Unrecognized message type: %s", baseMsg.Type)
+		client.sendError(baseMsg.MessageID, types.ReasonUnknownMessage, "unrecognized message type "+baseMsg.Type)
+		return
+	}
 
+	// 3. Refuser les messages qui se font passer pour un autre utilisateur
+	if baseMsg.UserID != "" && baseMsg.UserID != client.User.ID {
+		log.Printf("[Warning] User %s sent %s claiming to be user %s", client.User.ID, baseMsg.Type, baseMsg.UserID)
+		client.sendError(baseMsg.MessageID, types.ReasonIdentityMismatch, "message user does not match the connected user")
+		return
+	}
+	if client.Room == nil && !roomlessMessageTypes[baseMsg.Type] {
+		log.Printf("[Warning] User %s sent %s without being in a room", client.User.Name, baseMsg.Type)
+		client.sendError(baseMsg.MessageID, types.ReasonNotInRoom, "join a room first")
+		return
+	}
+
+	// Les spectateurs ne peuvent pas agir sur la partie
+	if client.Room != nil && client.Room.isSpectator(client) && !canSpectatorSend(baseMsg.Type) {
+		log.Printf("[Warning] Spectator %s sent %s", client.User.Name, baseMsg.Type)
+		client.sendActionResult(baseMsg.MessageID, baseMsg.Type, errSpectator)
+		return
+	}
+
+	// 4. Vérifier que la phase de jeu de la room accepte ce message
+	if !roomlessMessageTypes[baseMsg.Type] {
+		phase := client.Room.gameManager.GetStatus()
+		if !game.IsMessageAllowed(phase, baseMsg.Type) {
+			log.Printf("[Warning] User %s sent %s during phase %s", client.User.Name, baseMsg.Type, phase)
+			client.sendActionResult(baseMsg.MessageID, baseMsg.Type, game.ErrWrongPhase)
+			return
 		}
 	}
+
+	handler(h, client, message) // Appeler dynamiquement la fonction
 }
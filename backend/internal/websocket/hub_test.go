@@ -0,0 +1,145 @@
+package websocket
+
+import (
+	"game-server/internal/game"
+	"game-server/internal/matchmaking"
+	"game-server/internal/types"
+	"testing"
+	"time"
+)
+
+// newTestHub returns a hub without rooms, clients nor store
+func newTestHub() *Hub {
+	return &Hub{
+		Clients:  make(map[*Client]bool),
+		rooms:    make(map[string]*Room),
+		sessions: NewSessionStore(),
+		queue:    matchmaking.NewQueue(),
+	}
+}
+
+func TestRoomIsKeptWhileAPlayerCanResume(t *testing.T) {
+	h := newTestHub()
+	r := newTestRoom(t)
+	h.rooms[r.ID] = r
+	alice := newTestClient(r, "alice")
+	alice.Session = h.sessions.Create(alice.User)
+	h.sessions.SetRoom(alice.Session, r.ID, false)
+	r.playerManager.UpdatePlayer("alice", types.Player{UserID: "alice", Character: game.NewCharacter("Alice", "red", "A")})
+
+	// Disconnecting keeps the session's room, so that the player can come back
+	h.LeaveRoom(alice)
+	if _, exists := h.rooms[r.ID]; !exists {
+		t.Fatal("the room was deleted while its player can resume into it")
+	}
+
+	alice.Session.LastSeen = time.Now().Add(-sessionTTL - time.Minute)
+	h.cleanUpRoom(r)
+	if _, exists := h.rooms[r.ID]; exists {
+		t.Error("the room was kept once the session of its player expired")
+	}
+	if !r.closed {
+		t.Error("the deleted room was not closed")
+	}
+}
+
+func TestRoomIsDeletedOnceItsGameIsOver(t *testing.T) {
+	h := newTestHub()
+	r := newTestRoom(t)
+	h.rooms[r.ID] = r
+	clients := []*Client{newTestClient(r, "alice"), newTestClient(r, "bob")}
+	for _, client := range clients {
+		client.Session = h.sessions.Create(client.User)
+		h.sessions.SetRoom(client.Session, r.ID, false)
+	}
+	startTestFight(t, r, clients, []string{"A", "B"})
+
+	h.LeaveRoom(clients[0])
+	h.LeaveRoom(clients[1])
+	if _, exists := h.rooms[r.ID]; !exists {
+		t.Fatal("the room was deleted during its fight")
+	}
+
+	if err := r.gameManager.SetGameStatus(game.PhaseFinished); err != nil {
+		t.Fatalf("SetGameStatus: %v", err)
+	}
+	h.cleanUpRoom(r)
+	if _, exists := h.rooms[r.ID]; exists {
+		t.Error("the room was kept once its game was over and its clients gone")
+	}
+}
+
+func TestClosedRoomSkipsScheduledWork(t *testing.T) {
+	tasks := make(chan func(), 1)
+	r := newTestRoom(t)
+	r.tasks = tasks
+	ran := false
+	r.after(0, func() { ran = true })
+	r.close()
+
+	(<-tasks)()
+	if ran {
+		t.Error("the work scheduled by a closed room ran")
+	}
+}
+
+func TestOutOfPhaseMessagesAreRefused(t *testing.T) {
+	h := newTestHub()
+	r := newTestRoom(t)
+	h.rooms[r.ID] = r
+	alice, bob := newTestClient(r, "alice"), newTestClient(r, "bob")
+
+	h.dispatch(alice, []byte(`{"type": "move", "messageId": "1", "position": {"x": 0, "y": 1}}`))
+	h.dispatch(alice, []byte(`{"type": "end_turn", "messageId": "2"}`))
+
+	startTestFight(t, r, []*Client{alice, bob}, []string{"A", "B"})
+	receivedTypes(t, bob)
+	h.dispatch(bob, []byte(`{"type": "create_character", "messageId": "3", "character": {"name": "bob", "color": "red", "symbol": "X"}}`))
+	h.dispatch(bob, []byte(`{"type": "ready_to_start", "messageId": "4"}`))
+
+	for _, client := range []*Client{alice, bob} {
+		var refused []string
+		for _, result := range receivedTypes(t, client)["action_result"] {
+			if result["code"] == types.ReasonWrongPhase {
+				refused = append(refused, result["action"].(string))
+			}
+		}
+		if len(refused) != 2 {
+			t.Errorf("%s got %v refused for the phase, want 2 actions", client.User.ID, refused)
+		}
+	}
+	if r.gameManager.GetStatus() != game.PhaseFighting {
+		t.Errorf("status = %q, want %q", r.gameManager.GetStatus(), game.PhaseFighting)
+	}
+}
+
+func TestMessagesClaimingAnotherUserAreRefused(t *testing.T) {
+	h := newTestHub()
+	r := newTestRoom(t)
+	h.rooms[r.ID] = r
+	alice, bob := newTestClient(r, "alice"), newTestClient(r, "bob")
+	startTestFight(t, r, []*Client{alice, bob}, []string{"A", "B"})
+	current, _ := r.gameManager.GetCurrentTurnPlayer()
+	other := alice
+	if current == "alice" {
+		other = bob
+	}
+	receivedTypes(t, other)
+
+	// The player who is waiting tries to end the turn of the current player
+	h.dispatch(other, mustMarshal(t, map[string]string{"type": "end_turn", "messageId": "1", "userId": current}))
+
+	errorMessages := receivedTypes(t, other)["error"]
+	if len(errorMessages) != 1 || errorMessages[0]["code"] != types.ReasonIdentityMismatch || errorMessages[0]["messageId"] != "1" {
+		t.Errorf("errors = %v, want one %s error", errorMessages, types.ReasonIdentityMismatch)
+	}
+	if next, _ := r.gameManager.GetCurrentTurnPlayer(); next != current {
+		t.Errorf("turn = %s, want %s: the message was handled", next, current)
+	}
+
+	// Its own user ID is accepted
+	h.dispatch(other, mustMarshal(t, map[string]string{"type": "chat", "messageId": "2", "userId": other.User.ID, "content": "hi"}))
+	if errorMessages := receivedTypes(t, other)["error"]; len(errorMessages) != 0 {
+		t.Errorf("errors = %v, want none for the user's own ID", errorMessages)
+	}
+}
@@ -0,0 +1,228 @@
+package websocket
+
+import (
+	"encoding/json"
+	"game-server/internal/game"
+	"game-server/internal/matchmaking"
+	"game-server/internal/types"
+	"log"
+	"time"
+)
+
+// matchmakingInterval is how often the queue is matched again while players wait,
+// so that the accepted rating gap widens for them
+const matchmakingInterval = 5 * time.Second
+
+var (
+	errRankedRoom      = &game.RuleError{Code: types.ReasonRankedRoom, Message: "not allowed in a ranked room"}
+	errAccountRequired = &game.RuleError{Code: types.ReasonAccountRequired, Message: "log in to play ranked matches"}
+)
+
+// handleJoinQueueMessage puts the requesting client in the matchmaking queue of a
+// mode. Ratings belong to accounts, so guests cannot queue.
+func handleJoinQueueMessage(h *Hub, c *Client, message []byte) {
+	var queueMessage types.QueueMessage
+	if err := json.Unmarshal(message, &queueMessage); err != nil {
+		log.Printf("[Error] Invalid join queue message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	if c.User.Guest {
+		c.sendActionResult(queueMessage.MessageID, "join_queue", errAccountRequired)
+		return
+	}
+	if _, fighting := h.fightingRoom(c.User.ID); fighting {
+		c.sendActionResult(queueMessage.MessageID, "join_queue", errInFight)
+		return
+	}
+
+	mode, exists := matchmaking.GetMode(queueMessage.Mode)
+	if !exists {
+		c.sendActionResult(queueMessage.MessageID, "join_queue", matchmaking.ErrUnknownMode)
+		return
+	}
+	rating, err := h.rating(c.User, mode.ID)
+	if err != nil {
+		log.Printf("[Error] Failed to read the rating of user %s: %v", c.User.ID, err)
+		c.sendActionResult(queueMessage.MessageID, "join_queue", err)
+		return
+	}
+
+	h.queue.Join(mode, matchmaking.Entry{
+		UserID:   c.User.ID,
+		UserName: c.User.Name,
+		Rating:   rating.Rating,
+		JoinedAt: time.Now(),
+	})
+	c.sendActionResult(queueMessage.MessageID, "join_queue", nil)
+	log.Printf("[Matchmaking] User %s queued for %s with rating %d", c.User.Name, mode.ID, rating.Rating)
+
+	h.runMatchmaking()
+}
+
+// handleLeaveQueueMessage takes the requesting client out of the matchmaking queue
+func handleLeaveQueueMessage(h *Hub, c *Client, message []byte) {
+	var queueMessage types.QueueMessage
+	if err := json.Unmarshal(message, &queueMessage); err != nil {
+		log.Printf("[Error] Invalid leave queue message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	h.queue.Leave(c.User.ID)
+	c.sendActionResult(queueMessage.MessageID, "leave_queue", nil)
+}
+
+// handleGetLeaderboardMessage sends the ratings of a mode to the requesting client
+func handleGetLeaderboardMessage(h *Hub, c *Client, message []byte) {
+	var queueMessage types.QueueMessage
+	if err := json.Unmarshal(message, &queueMessage); err != nil {
+		log.Printf("[Error] Invalid get leaderboard message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	ratings, err := h.leaderboard(queueMessage.Mode)
+	if err != nil {
+		c.sendActionResult(queueMessage.MessageID, "get_leaderboard", err)
+		return
+	}
+	c.sendMessage(types.LeaderboardMessage{
+		Type:    "leaderboard",
+		Mode:    queueMessage.Mode,
+		Ratings: ratings,
+	})
+}
+
+// rating returns the rating of a user in a mode, the default one if it never played it
+func (h *Hub) rating(user *types.User, mode string) (types.Rating, error) {
+	if h.store == nil {
+		return matchmaking.NewRating(user.ID, user.Name, mode), nil
+	}
+	rating, exists, err := h.store.GetRating(user.ID, mode)
+	if err != nil {
+		return types.Rating{}, err
+	}
+	if !exists {
+		return matchmaking.NewRating(user.ID, user.Name, mode), nil
+	}
+	return rating, nil
+}
+
+// leaderboard returns the ratings of a mode, best first
+func (h *Hub) leaderboard(mode string) ([]types.Rating, error) {
+	if _, exists := matchmaking.GetMode(mode); !exists {
+		return nil, matchmaking.ErrUnknownMode
+	}
+	if h.store == nil {
+		return []types.Rating{}, nil
+	}
+	ratings, err := h.store.ListRatings(mode)
+	if err != nil {
+		return nil, err
+	}
+	if ratings == nil {
+		ratings = []types.Rating{}
+	}
+	return ratings, nil
+}
+
+// runMatchmaking starts every match the queue can form, then matches the queue
+// again later while players are still waiting
+func (h *Hub) runMatchmaking() {
+	for _, match := range h.queue.Match(time.Now()) {
+		h.startMatch(match)
+	}
+
+	if h.queue.Len() > 0 && !h.matchmakingScheduled {
+		h.matchmakingScheduled = true
+		h.after(matchmakingInterval, func() {
+			h.matchmakingScheduled = false
+			h.runMatchmaking()
+		})
+	}
+}
+
+// startMatch creates a ranked room for a match and moves its players into their
+// teams. A match with a player who is gone, or who started fighting elsewhere
+// meanwhile, is cancelled instead, and the other players wait in the queue again.
+func (h *Hub) startMatch(match matchmaking.Match) {
+	clients := make(map[string]*Client)
+	var available []matchmaking.Entry
+	for _, entries := range match.Teams {
+		for _, entry := range entries {
+			client := h.clientByUserID(entry.UserID)
+			if _, fighting := h.fightingRoom(entry.UserID); client != nil && !fighting {
+				clients[entry.UserID] = client
+				available = append(available, entry)
+			}
+		}
+	}
+	if len(available) < 2*match.Mode.TeamSize {
+		log.Printf("[Matchmaking] Cancelled a %s match: %d of its players are gone or fighting", match.Mode.ID, 2*match.Mode.TeamSize-len(available))
+		h.queue.Requeue(match.Mode, available)
+		return
+	}
+
+	room, err := h.CreateRoom("Ranked "+match.Mode.ID, RoomSettings{FriendlyFire: true})
+	if err != nil {
+		log.Printf("[Error] Failed to create a ranked room: %v", err)
+		h.queue.Requeue(match.Mode, available)
+		return
+	}
+	room.mode = match.Mode.ID
+	room.assignedTeams = make(map[string]string)
+
+	teams := room.gameManager.Teams()
+	for i, entries := range match.Teams {
+		for _, entry := range entries {
+			room.assignedTeams[entry.UserID] = teams[i]
+		}
+	}
+	log.Printf("[Matchmaking] Started a %s match in room %s", match.Mode.ID, room.ID)
+
+	for userID, team := range room.assignedTeams {
+		client := clients[userID]
+		moveClientToRoom(h, client, room, false)
+		client.sendMessage(types.MatchFoundMessage{
+			Type:   "match_found",
+			Mode:   match.Mode.ID,
+			RoomID: room.ID,
+			Team:   team,
+		})
+	}
+}
+
+// updateRatings rates the players of a ranked room once its fight is over
+func (r *Room) updateRatings(winningTeam string) {
+	if r.mode == "" || r.store == nil {
+		return
+	}
+
+	teams := make(map[string][]types.Rating)
+	for userID, player := range r.gameManager.GetCurrentState().Players {
+		if player.IsBot {
+			continue
+		}
+		rating, exists, err := r.store.GetRating(userID, r.mode)
+		if err != nil {
+			log.Printf("[Error] Failed to read the rating of user %s: %v", userID, err)
+			return
+		}
+		if !exists {
+			rating = matchmaking.NewRating(userID, player.UserName, r.mode)
+		}
+		rating.UserName = player.UserName
+		teams[player.Team] = append(teams[player.Team], rating)
+	}
+
+	ratings := matchmaking.UpdateRatings(teams, winningTeam, time.Now())
+	if err := r.store.SaveRatings(ratings); err != nil {
+		log.Printf("[Error] Failed to save ratings: %v", err)
+		return
+	}
+	for _, rating := range ratings {
+		log.Printf("[Matchmaking] User %s is now rated %d in %s", rating.UserName, rating.Rating, r.mode)
+	}
+}
@@ -0,0 +1,114 @@
+package websocket
+
+import (
+	"game-server/internal/game"
+	"game-server/internal/matchmaking"
+	"game-server/internal/storage"
+	"game-server/internal/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGuestsCannotQueue(t *testing.T) {
+	h := newTestHub()
+	guest := &Client{ID: "client-guest", Send: make(chan []byte, 16), User: &types.User{ID: "guest", Name: "guest", Guest: true}}
+
+	handleJoinQueueMessage(h, guest, []byte(`{"type": "join_queue", "messageId": "1", "mode": "1v1"}`))
+
+	if h.queue.Len() != 0 {
+		t.Error("a guest joined the queue")
+	}
+	results := receivedTypes(t, guest)["action_result"]
+	if len(results) != 1 || results[0]["code"] != types.ReasonAccountRequired {
+		t.Errorf("results = %v, want one %s result", results, types.ReasonAccountRequired)
+	}
+}
+
+func TestMatchWithAPlayerGoneIsCancelled(t *testing.T) {
+	h := newTestHub()
+	alice := &Client{ID: "client-alice", Send: make(chan []byte, 16), User: &types.User{ID: "alice", Name: "alice"}}
+	h.Clients[alice] = true
+
+	mode, _ := matchmaking.GetMode("1v1")
+	joinedAt := time.Now().Add(-time.Minute)
+	h.startMatch(matchmaking.Match{Mode: mode, Teams: [2][]matchmaking.Entry{
+		{{UserID: "alice", UserName: "alice", Rating: 1000, JoinedAt: joinedAt}},
+		{{UserID: "bob", UserName: "bob", Rating: 1000, JoinedAt: joinedAt}},
+	}})
+
+	if len(h.rooms) != 0 {
+		t.Errorf("%d rooms were created for a cancelled match", len(h.rooms))
+	}
+	if h.queue.Len() != 1 || !h.queue.Leave("alice") {
+		t.Error("the connected player was not put back in the queue")
+	}
+	if received := receivedTypes(t, alice); len(received["match_found"]) != 0 {
+		t.Error("the connected player was told the match was found")
+	}
+}
+
+func TestFightersCannotQueueNorLeaveTheirFight(t *testing.T) {
+	h := newTestHub()
+	fight := newTestRoom(t)
+	clients := []*Client{newTestClient(fight, "alice"), newTestClient(fight, "bob")}
+	startTestFight(t, fight, clients, []string{"A", "B"})
+	other := newTestRoom(t)
+	other.ID = "other-room"
+	h.rooms[fight.ID] = fight
+	h.rooms[other.ID] = other
+	alice := clients[0]
+	receivedTypes(t, alice)
+
+	handleJoinQueueMessage(h, alice, []byte(`{"type": "join_queue", "messageId": "1", "mode": "1v1"}`))
+	handleCreateRoomMessage(h, alice, []byte(`{"type": "create_room", "messageId": "2"}`))
+	handleJoinRoomMessage(h, alice, []byte(`{"type": "join_room", "messageId": "3", "roomId": "other-room"}`))
+	handleLeaveRoomMessage(h, alice, []byte(`{"type": "leave_room", "messageId": "4"}`))
+
+	if h.queue.Len() != 0 {
+		t.Error("a fighter joined the queue")
+	}
+	if alice.Room != fight || len(h.rooms) != 2 {
+		t.Error("a fighter left its fight")
+	}
+	results := receivedTypes(t, alice)["action_result"]
+	if len(results) != 4 {
+		t.Fatalf("results = %v, want 4", results)
+	}
+	for _, result := range results {
+		if result["code"] != types.ReasonInFight {
+			t.Errorf("%s result code = %v, want %s", result["action"], result["code"], types.ReasonInFight)
+		}
+	}
+}
+
+func TestRankedResultsRateOnlyHumans(t *testing.T) {
+	r := newTestRoom(t)
+	store := storage.NewMemoryStore()
+	r.mode, r.store = "1v1", store
+	if err := store.SaveRatings([]types.Rating{matchmaking.NewRating("bob", "bob", "1v1")}); err != nil {
+		t.Fatalf("SaveRatings: %v", err)
+	}
+	if err := r.gameManager.StartGame(map[string]types.Player{
+		"alice": {UserID: "alice", UserName: "alice", Team: "A", IsReady: true, Character: game.NewCharacter("alice", "red", "A")},
+		"bot-1": {UserID: "bot-1", UserName: "Bot 1", Team: "A", IsReady: true, IsBot: true, Character: game.NewCharacter("Bot 1", botColor, "B")},
+		"bob":   {UserID: "bob", UserName: "bob", Team: "B", IsReady: true, Character: game.NewCharacter("bob", "blue", "B")},
+	}); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+
+	r.updateRatings("A")
+
+	ratings, err := store.ListRatings("1v1")
+	if err != nil {
+		t.Fatalf("ListRatings: %v", err)
+	}
+	got := make(map[string]int)
+	for _, rating := range ratings {
+		got[rating.UserID] = rating.Rating
+	}
+	want := map[string]int{"alice": 1516, "bob": 1484}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ratings = %v, want %v", got, want)
+	}
+}
@@ -2,12 +2,12 @@ package websocket
 
 import (
 	"encoding/json"
+	"game-server/internal/game"
 	"game-server/internal/types"
 	"log"
-	"strconv"
 )
 
-type MessageHandler func(*Hub, []byte)
+type MessageHandler func(*Hub, *Client, []byte)
 
 var messageHandlers = map[string]MessageHandler{
 	"chat":                 handleChatMessage,
@@ -18,119 +18,60 @@ var messageHandlers = map[string]MessageHandler{
 	"character_positioned": handleCharacterPositionedMessage,
 	"end_turn":             handleEndTurnMessage,
 	"cast_spell":           handleCastSpellMessage,
+	"list_rooms":           handleListRoomsMessage,
+	"create_room":          handleCreateRoomMessage,
+	"join_room":            handleJoinRoomMessage,
+	"leave_room":           handleLeaveRoomMessage,
+	"return_to_lobby":      handleReturnToLobbyMessage,
+	"join_team":            handleJoinTeamMessage,
+	"add_bot":              handleAddBotMessage,
+	"remove_bot":           handleRemoveBotMessage,
+	"watch_replay":         handleWatchReplayMessage,
+	"stop_replay":          handleStopReplayMessage,
+	"join_queue":           handleJoinQueueMessage,
+	"leave_queue":          handleLeaveQueueMessage,
+	"get_leaderboard":      handleGetLeaderboardMessage,
+	"sync_mode":            handleSyncModeMessage,
+	"sync_request":         handleSyncRequestMessage,
+	"get_reachable_cells":  handleGetReachableCellsMessage,
+	"get_spell_targets":    handleGetSpellTargetsMessage,
 }
 
-// Final turn handler for the end turn message.
-// 1. Update the character's hasPlayedThisTurn to true
-// 2. Update the player's isCurrentTurn to false
-// 3. Find the next character in the turn that has not played this turn
-// 4. Set the next character's isCurrentTurn to true
-// 5. Set isCurrentTurn to true of the next players with the next character as current turn
-// 6. Broadcast the updated state
-func handleEndTurnMessage(h *Hub, message []byte) {
+// Message types that can be handled for a client that is not in any room
+var roomlessMessageTypes = map[string]bool{
+	"list_rooms":      true,
+	"create_room":     true,
+	"join_room":       true,
+	"leave_room":      true,
+	"watch_replay":    true,
+	"stop_replay":     true,
+	"join_queue":      true,
+	"leave_queue":     true,
+	"get_leaderboard": true,
+}
+
+// handleEndTurnMessage ends the sender's turn and hands it to the next character
+func handleEndTurnMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
+
 	var endTurnMessage types.EndTurnMessage
 	if err := json.Unmarshal(message, &endTurnMessage); err != nil {
 		log.Printf("[Error] Invalid end turn message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
 		return
 	}
 
-	// Update character hasPlayedThisTurn to true
-	if err := h.gameManager.SetHasPlayedThisTurn(endTurnMessage.UserID, true); err != nil {
-		log.Printf("[Error] Failed to update player hasPlayedThisTurn: %v", err)
-		return
-	}
-
-	// Update player's isCurrentTurn to false
-	if err := h.playerManager.SetPlayerCurrentTurn(endTurnMessage.UserID, false); err != nil {
-		log.Printf("[Error] Failed to update player's game state: %v", err)
+	if err := r.endTurn(c.User.ID); err != nil {
+		log.Printf("[Error] Rejected end of turn of player %s: %v", c.User.ID, err)
+		c.sendActionResult(endTurnMessage.MessageID, "end_turn", err)
 		return
 	}
-	// Check if all players have played this turn
-	players := h.playerManager.GetPlayers()
-	allPlayed := true
-	for _, player := range players {
-		if !player.Character.HasPlayedThisTurn {
-			allPlayed = false
-			break
-		}
-	}
-
-	if allPlayed {
-		for userID := range players {
-			// Reset character's HasPlayedThisTurn
-			if err := h.gameManager.SetHasPlayedThisTurn(userID, false); err != nil {
-				log.Printf("[Error] Failed to reset player hasPlayedThisTurn: %v", err)
-				return
-			}
-			// Reset the player's MP
-			if err := h.playerManager.ResetMPs(userID); err != nil {
-				log.Printf("[Error] Failed to reset player MP: %v", err)
-				return
-			}
-			// Reset the player's AP
-			if err := h.playerManager.ResetAPs(userID); err != nil {
-				log.Printf("[Error] Failed to reset player AP: %v", err)
-				return
-			}
-
-		}
-		h.gameManager.IncrementTurnNumber()
-		h.playerManager.SetFirstCharacter()
-	} else {
-		// Find the next character in the turn that has not played this turn
-		nextCharacter, err := h.gameManager.GetNextCharacter()
-		if err != nil {
-			log.Printf("[Error] Failed to get next character: %v", err)
-			return
-		}
-
-		// Set the next character's isCurrentTurn to True
-		if err := h.gameManager.SetCharacterCurrentTurn(nextCharacter.Name, true); err != nil {
-			log.Printf("[Error] Failed to set next player as current turn: %v", err)
-			return
-		}
-
-		// Get the user ID given a character name
-		nextUserID := h.playerManager.GetUserIDWithCharacterName(nextCharacter.Name)
-		if nextUserID == "" {
-			log.Printf("[Error] No player has the current turn character")
-			return
-		}
-
-		// Update the player whose turn it is now
-		if err := h.playerManager.SetPlayerCurrentTurn(nextUserID, true); err != nil {
-			log.Printf("[Error] Failed to update player's game state: %v", err)
-			return
-		}
-		// Reset the player's AP
-		if err := h.playerManager.ResetAPs(nextUserID); err != nil {
-			log.Printf("[Error] Failed to reset player AP: %v", err)
-			return
-		}
-	}
-
-	// Check for game over condition
-	winnerID, gameOver := h.gameManager.CheckGameOver()
-	if gameOver {
-		log.Printf("[Game Over] Winner: %s", winnerID)
-		// Get winner's name
-		winnerPlayer, exists := h.playerManager.GetPlayer(winnerID)
-		winnerName := ""
-		if exists {
-			winnerName = winnerPlayer.UserName
-		}
-		gameOverMessage, _ := json.Marshal(types.GameOverMessage{Type: "game_over", Winner: winnerName})
-		h.broadcastMessage(gameOverMessage)
-		return
-	}
-
-	// Broadcast the updated state
-	if err := h.BroadcastGameState(); err != nil {
-		log.Printf("[Error] Failed to broadcast game state: %v", err)
-	}
+	c.sendActionResult(endTurnMessage.MessageID, "end_turn", nil)
 }
 
-func handleDisconnectMessage(h *Hub, message []byte) {
+func handleDisconnectMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
+
 	var disconnectMessage types.DisconnectMessage
 	if err := json.Unmarshal(message, &disconnectMessage); err != nil {
 		log.Printf("[Error] Invalid disconnect message: %v", err)
@@ -138,253 +79,323 @@ func handleDisconnectMessage(h *Hub, message []byte) {
 
 	}
 
-	log.Printf("[Disconnect] User %s left the game", disconnectMessage.UserName)
+	log.Printf("[Disconnect] User %s left the game", c.User.Name)
 
-	// Use the safe method to remove player
-	h.playerManager.RemovePlayer(disconnectMessage.UserID)
+	// Characters already in a fight are kept so that the user can resume its session
+	removePlayerBeforeFight(r, c.User.ID)
 
 	// Broadcast the updated state
-	if err := h.BroadcastGameState(); err != nil {
+	if err := r.BroadcastGameState(); err != nil {
 		log.Printf("[Error] Failed to broadcast game state: %v", err)
 	}
 }
 
-func handleChatMessage(h *Hub, message []byte) {
+func handleChatMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
+
 	var chatMessage types.ChatMessage
 	if err := json.Unmarshal(message, &chatMessage); err != nil {
 		log.Printf("[Error] Invalid chat message: %v", err)
 		return
 	}
-	log.Printf("[Chat] Message received from UserID: %s, Content: %s", chatMessage.UserID, chatMessage.Content)
-	h.broadcastMessage(message)
+	log.Printf("[Chat] Message received from UserID: %s, Content: %s", c.User.ID, chatMessage.Content)
+
+	// Re-encode the message so that the sender shown to others is the connected user
+	chatMessage.UserID = c.User.ID
+	chatMessage.UserName = c.User.Name
+	outgoing, err := json.Marshal(chatMessage)
+	if err != nil {
+		log.Printf("[Error] Failed to marshal chat message: %v", err)
+		return
+	}
+	r.broadcastMessage(outgoing)
 }
 
-func handleCreateCharacterMessage(h *Hub, message []byte) {
+func handleCreateCharacterMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
+
 	var createCharacterMessage types.CreateCharacter
 	if err := json.Unmarshal(message, &createCharacterMessage); err != nil {
 		log.Printf("[Error] Invalid create character message: %v", err)
 		return
 	}
 
-	// Set initial health and alive status on the backend
-	createCharacterMessage.Character.Health = 100
-	createCharacterMessage.Character.IsAlive = true
+	// Only the matched players play in a ranked room
+	team, assigned := r.assignedTeams[c.User.ID]
+	if r.mode != "" && !assigned {
+		c.sendActionResult(createCharacterMessage.MessageID, "create_character", errRankedRoom)
+		return
+	}
+
+	// Only the name and look come from the client, the stats are set on the backend
+	sent := createCharacterMessage.Character
+	if sent == nil {
+		c.sendError(createCharacterMessage.MessageID, types.ReasonInvalidMessage, "character is required")
+		return
+	}
+	character := game.NewCharacter(sent.Name, sent.Color, sent.Symbol)
+
+	// Keep the team of a player updating its character, fill the smallest team otherwise
+	if !assigned {
+		team = game.SmallestTeam(r.gameManager.Teams(), r.playerManager.GetPlayers())
+		if existing, exists := r.playerManager.GetPlayer(c.User.ID); exists && existing.Team != "" {
+			team = existing.Team
+		}
+	}
 
 	newPlayer := types.Player{
-		Character:     createCharacterMessage.Character,
+		Team:          team,
+		Character:     character,
 		IsCurrentTurn: false,
-		UserName:      createCharacterMessage.UserName,
-		UserID:        createCharacterMessage.UserID,
+		UserName:      c.User.Name,
+		UserID:        c.User.ID,
 		Status:        "waiting-room",
 	}
 
 	// Use the safe method to add player
-	h.playerManager.UpdatePlayer(createCharacterMessage.UserID, newPlayer)
+	r.playerManager.UpdatePlayer(c.User.ID, newPlayer)
 
 	// Broadcast the updated state
-	if err := h.BroadcastGameState(); err != nil {
+	if err := r.BroadcastGameState(); err != nil {
 		log.Printf("[Error] Failed to broadcast game state: %v", err)
 	}
 }
 
-func handleReadyToStartMessage(h *Hub, message []byte) {
-	var readyMessage types.IsReadyMessage
-	if err := json.Unmarshal(message, &readyMessage); err != nil {
-		log.Printf("[Error] Invalid ready to start message: %v", err)
+// handleJoinTeamMessage moves the sender's player to another team before the fight
+func handleJoinTeamMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
+
+	var joinTeamMessage types.JoinTeamMessage
+	if err := json.Unmarshal(message, &joinTeamMessage); err != nil {
+		log.Printf("[Error] Invalid join team message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
 		return
 	}
 
-	// Update player status
-	h.playerManager.PlayerReadyToStart(readyMessage)
-
-	// Check if all players are ready and there are at least 2 players
-	players := h.playerManager.GetPlayers()
-	if len(players) >= 2 {
-		allReady := true
-		for _, player := range players {
-			if !player.IsReady {
-				allReady = false
-				break
-			}
-		}
-
-		// If all players are ready, start the game
-		if allReady {
-			if err := h.gameManager.StartGame(players); err != nil {
-				log.Printf("[Error] Failed to start game: %v", err)
-				return
-			}
-			h.playerManager.SetFirstCharacter()
-		}
+	if r.mode != "" {
+		c.sendActionResult(joinTeamMessage.MessageID, "join_team", errRankedRoom)
+		return
+	}
+	if !r.gameManager.IsTeam(joinTeamMessage.Team) {
+		c.sendActionResult(joinTeamMessage.MessageID, "join_team", game.ErrUnknownTeam)
+		return
+	}
+	if err := r.playerManager.SetPlayerTeam(c.User.ID, joinTeamMessage.Team); err != nil {
+		log.Printf("[Error] Failed to set player team: %v", err)
+		c.sendActionResult(joinTeamMessage.MessageID, "join_team", game.ErrPlayerNotFound)
+		return
 	}
+	c.sendActionResult(joinTeamMessage.MessageID, "join_team", nil)
 
 	// Broadcast the updated state
-	if err := h.BroadcastGameState(); err != nil {
+	if err := r.BroadcastGameState(); err != nil {
 		log.Printf("[Error] Failed to broadcast game state: %v", err)
 	}
 }
 
-/*
-1. Unmarshal the message into a CastSpellMessage struct.
-2. Retrieve the AP cost from the total AP of the player.
-3. Apply damage or effects of the spell to target positions.
-4. Broadcast the updated game state to all players.
-*/
-func handleCastSpellMessage(h *Hub, message []byte) {
-	var castSpellMessage types.CastSpellMessage
-	if err := json.Unmarshal(message, &castSpellMessage); err != nil {
-		log.Printf("[Error] Invalid cast spell message: %v", err)
-		return
-	}
+// handleAddBotMessage adds a server-played opponent or teammate to the lobby
+func handleAddBotMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
 
-	spellIDStr := strconv.Itoa(castSpellMessage.SpellID)
-
-	// Get the casting player's current AP
-	currentPlayer, exists := h.playerManager.GetPlayer(castSpellMessage.UserID)
-	if !exists || currentPlayer.Character == nil {
-		log.Printf("[Error] Caster player or character not found for UserID: %s", castSpellMessage.UserID)
+	var addBotMessage types.AddBotMessage
+	if err := json.Unmarshal(message, &addBotMessage); err != nil {
+		log.Printf("[Error] Invalid add bot message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
 		return
 	}
-	currentAP := currentPlayer.Character.ActionPoints
 
-	// Compute the spell cost
-	spellCost, err := h.gameManager.GetSpellCost(spellIDStr)
-	if err != nil {
-		log.Printf("[Error] Failed to get spell cost: %v", err)
+	if _, err := r.addBot(addBotMessage.Difficulty, addBotMessage.Team); err != nil {
+		c.sendActionResult(addBotMessage.MessageID, "add_bot", err)
 		return
 	}
+	c.sendActionResult(addBotMessage.MessageID, "add_bot", nil)
 
-	// Check if player has enough AP
-	if currentAP < spellCost {
-		log.Printf("[Error] Player %s does not have enough AP to cast spell %s. Current AP: %d, Required AP: %d", castSpellMessage.UserID, spellIDStr, currentAP, spellCost)
-		return
+	// Broadcast the updated state
+	if err := r.BroadcastGameState(); err != nil {
+		log.Printf("[Error] Failed to broadcast game state: %v", err)
 	}
+}
+
+// handleRemoveBotMessage removes a bot from the lobby
+func handleRemoveBotMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
 
-	// Retrieve AP cost from the total AP of the player
-	if err := h.gameManager.UpdatePlayerAP(castSpellMessage.UserID, spellCost); err != nil {
-		log.Printf("[Error] Failed to update player AP: %v", err)
+	var removeBotMessage types.RemoveBotMessage
+	if err := json.Unmarshal(message, &removeBotMessage); err != nil {
+		log.Printf("[Error] Invalid remove bot message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
 		return
 	}
 
-	// Get caster's position
-	casterPlayer, exists := h.playerManager.GetPlayer(castSpellMessage.UserID)
-	if !exists {
-		log.Printf("[Error] Failed to get caster's player data")
+	if err := r.removeBot(removeBotMessage.BotID); err != nil {
+		c.sendActionResult(removeBotMessage.MessageID, "remove_bot", err)
 		return
 	}
-	casterPosition := casterPlayer.Character.Position
+	c.sendActionResult(removeBotMessage.MessageID, "remove_bot", nil)
 
-	// Get affected positions
-	affectedPositions, err := h.gameManager.GetAffectedPositions(spellIDStr, castSpellMessage.TargetPosition, *casterPosition)
-	if err != nil {
-		log.Printf("[Error] Failed to get affected positions: %v", err)
-		return
+	// Broadcast the updated state
+	if err := r.BroadcastGameState(); err != nil {
+		log.Printf("[Error] Failed to broadcast game state: %v", err)
 	}
+}
+
+func handleReadyToStartMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
 
-	// Apply damage or effects of the spell to target positions
-	if err := h.gameManager.ApplySpellDamages(spellIDStr, affectedPositions); err != nil {
-		log.Printf("[Error] Failed to apply spell damages: %v", err)
+	var readyMessage types.IsReadyMessage
+	if err := json.Unmarshal(message, &readyMessage); err != nil {
+		log.Printf("[Error] Invalid ready to start message: %v", err)
 		return
 	}
 
-	// Check for game over condition
-	winnerID, gameOver := h.gameManager.CheckGameOver()
-	if gameOver {
-		log.Printf("[Game Over] Winner: %s", winnerID)
-		// Get winner's name
-		winnerPlayer, exists := h.playerManager.GetPlayer(winnerID)
-		winnerName := ""
-		if exists {
-			winnerName = winnerPlayer.UserName
+	// Update player status
+	r.playerManager.PlayerReadyToStart(c.User.ID)
+
+	// Check if all players are ready and there are at least 2 players
+	players := r.playerManager.GetPlayers()
+	if len(players) >= 2 {
+		allReady := true
+		for _, player := range players {
+			if !player.IsReady {
+				allReady = false
+				break
+			}
+		}
+
+		// If all players are ready, start the game. The players still see who is
+		// ready when it cannot start, all in one team for instance.
+		if allReady {
+			if err := r.gameManager.StartGame(players); err != nil {
+				log.Printf("[Error] Failed to start game: %v", err)
+				c.sendError(readyMessage.MessageID, game.ReasonCode(err), err.Error())
+			} else {
+				// Bots take the first cell they are offered
+				r.placeBots()
+			}
 		}
-		gameOverMessage, _ := json.Marshal(types.GameOverMessage{Type: "game_over", Winner: winnerName})
-		h.broadcastMessage(gameOverMessage)
-		return
 	}
 
 	// Broadcast the updated state
-	if err := h.BroadcastGameState(); err != nil {
+	if err := r.BroadcastGameState(); err != nil {
 		log.Printf("[Error] Failed to broadcast game state: %v", err)
 	}
 }
 
-func handleMoveMessage(h *Hub, message []byte) {
-	var moveMessage types.MoveMessage
-	if err := json.Unmarshal(message, &moveMessage); err != nil {
-		log.Printf("[Error] Invalid move message: %v", err)
-		return
-	}
+// handleCastSpellMessage casts a spell from the sender's character
+func handleCastSpellMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
 
-	// Update player postion points
-	if err := h.gameManager.UpdatePlayerPM(moveMessage.UserID, moveMessage.Position); err != nil {
-		log.Printf("[Error] Failed to update player position points: %v", err)
+	var castSpellMessage types.CastSpellMessage
+	if err := json.Unmarshal(message, &castSpellMessage); err != nil {
+		log.Printf("[Error] Invalid cast spell message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
 		return
 	}
-	// Update player position
-	if err := h.gameManager.UpdatePlayerPosition(moveMessage.UserID, moveMessage.Position); err != nil {
-		log.Printf("[Error] Failed to start game: %v", err)
+
+	if err := r.castSpell(c.User.ID, castSpellMessage.SpellID, castSpellMessage.TargetPosition); err != nil {
+		log.Printf("[Error] Rejected cast of spell %d by player %s on %+v: %v", castSpellMessage.SpellID, c.User.ID, castSpellMessage.TargetPosition, err)
+		c.sendActionResult(castSpellMessage.MessageID, "cast_spell", err)
 		return
 	}
+	c.sendActionResult(castSpellMessage.MessageID, "cast_spell", nil)
+}
 
-	// Check for game over condition
-	winnerID, gameOver := h.gameManager.CheckGameOver()
-	if gameOver {
-		log.Printf("[Game Over] Winner: %s", winnerID)
-		// Get winner's name
-		winnerPlayer, exists := h.playerManager.GetPlayer(winnerID)
-		winnerName := ""
-		if exists {
-			winnerName = winnerPlayer.UserName
-		}
-		gameOverMessage, _ := json.Marshal(types.GameOverMessage{Type: "game_over", Winner: winnerName})
-		h.broadcastMessage(gameOverMessage)
+// handleMoveMessage moves the sender's character
+func handleMoveMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
+
+	var moveMessage types.MoveMessage
+	if err := json.Unmarshal(message, &moveMessage); err != nil {
+		log.Printf("[Error] Invalid move message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
 		return
 	}
 
-	// Broadcast the updated state
-	if err := h.BroadcastGameState(); err != nil {
-		log.Printf("[Error] Failed to broadcast game state: %v", err)
+	if err := r.moveCharacter(c.User.ID, moveMessage.Position); err != nil {
+		log.Printf("[Error] Rejected move of player %s to %+v: %v", c.User.ID, moveMessage.Position, err)
+		c.sendActionResult(moveMessage.MessageID, "move", err)
+		return
 	}
+	c.sendActionResult(moveMessage.MessageID, "move", nil)
 }
 
 // handleCharacterPositionedMessage handles the "character_positioned" message.
 // It is called when a player has placed their character during the setup phase.
 // Once all players have placed their characters, the game status is set to "in_progress".
-func handleCharacterPositionedMessage(h *Hub, message []byte) {
+func handleCharacterPositionedMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
+
 	var positionedMessage types.CharacterPositionedMessage
 	if err := json.Unmarshal(message, &positionedMessage); err != nil {
 		log.Printf("[Error] Invalid character positioned message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
 		return
 	}
 
-	// Store the chosen initial position
-	h.gameManager.SetChosenInitialPosition(positionedMessage.UserID, positionedMessage.Position)
-
-	// Set player as positioned
-	if err := h.playerManager.SetPlayerHasPositioned(positionedMessage.UserID, true); err != nil {
-		log.Printf("[Error] Failed to set player as positioned: %v", err)
+	// Store the chosen initial position, which must be one of the offered placement cells
+	if err := r.gameManager.SetChosenInitialPosition(c.User.ID, positionedMessage.Position); err != nil {
+		log.Printf("[Error] Rejected placement of player %s on %+v: %v", c.User.ID, positionedMessage.Position, err)
+		c.sendActionResult(positionedMessage.MessageID, "character_positioned", err)
 		return
 	}
 
+	c.sendActionResult(positionedMessage.MessageID, "character_positioned", nil)
+
 	// Check if all players have positioned their characters
-	players := h.playerManager.GetPlayers()
-	if h.gameManager.AreAllPlayersPositioned(len(players)) {
+	players := r.gameManager.GetCurrentState().Players
+	if r.gameManager.AreAllPlayersPositioned(len(players)) {
 		// Apply all chosen positions to the game state
-		if err := h.gameManager.ApplyAllChosenPositions(); err != nil {
+		if err := r.gameManager.ApplyAllChosenPositions(); err != nil {
 			log.Printf("[Error] Failed to apply chosen positions: %v", err)
 			return
 		}
 
-		// If all players have positioned their characters, update the game status to "in_progress"
-		h.gameManager.SetGameStatus(types.GameStatusPlaying)
-		// Set the turn number to 1
-		h.gameManager.IncrementTurnNumber()
-		// Set the first character to play
-		h.playerManager.SetFirstCharacter()
+		// If all players have positioned their characters, the fight begins
+		if err := r.gameManager.SetGameStatus(game.PhaseFighting); err != nil {
+			log.Printf("[Error] Failed to start the fight: %v", err)
+			return
+		}
+		// Compute the turn order and start the first turn
+		firstUserID, err := r.gameManager.StartFight()
+		if err != nil {
+			log.Printf("[Error] Failed to compute the turn order: %v", err)
+			return
+		}
+		r.resetTimeBanks()
+		if err := r.startTurn(firstUserID); err != nil {
+			log.Printf("[Error] Failed to start the first turn: %v", err)
+			return
+		}
+	}
+
+	// Broadcast the updated state
+	if err := r.BroadcastGameState(); err != nil {
+		log.Printf("[Error] Failed to broadcast game state: %v", err)
+	}
+}
+
+// handleReturnToLobbyMessage starts a new game in the room once the fight is over.
+// Players keep their characters but have to get ready again.
+func handleReturnToLobbyMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
+
+	var lobbyMessage types.BaseMessage
+	if err := json.Unmarshal(message, &lobbyMessage); err != nil {
+		log.Printf("[Error] Invalid return to lobby message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	if err := r.gameManager.ResetToLobby(); err != nil {
+		log.Printf("[Error] Failed to return to lobby: %v", err)
+		c.sendActionResult(lobbyMessage.MessageID, "return_to_lobby", game.ErrWrongPhase)
+		return
 	}
+	r.playerManager.ResetForNewGame()
+	c.sendActionResult(lobbyMessage.MessageID, "return_to_lobby", nil)
 
 	// Broadcast the updated state
-	if err := h.BroadcastGameState(); err != nil {
+	if err := r.BroadcastGameState(); err != nil {
 		log.Printf("[Error] Failed to broadcast game state: %v", err)
 	}
 }
@@ -0,0 +1,153 @@
+package websocket
+
+import (
+	"encoding/json"
+	"game-server/internal/game"
+	"game-server/internal/game/gametest"
+	"game-server/internal/types"
+	"testing"
+)
+
+// newTestRoom returns a lobby on a small two-team board
+func newTestRoom(t *testing.T) *Room {
+	t.Helper()
+	return newTestRoomWithSpells(t, gametest.Spells)
+}
+
+// newTestRoomWithSpells returns a lobby on a small two-team board, with the
+// spells of a JSON catalogue
+func newTestRoomWithSpells(t *testing.T, catalogue string) *Room {
+	t.Helper()
+	spells, err := game.ParseSpellCatalogue([]byte(catalogue))
+	if err != nil {
+		t.Fatalf("failed to parse spells: %v", err)
+	}
+	board, err := game.NewBoard(gametest.BoardMap())
+	if err != nil {
+		t.Fatalf("failed to build board: %v", err)
+	}
+	return NewRoom("test-room", "Test room", spells, board, make(chan func(), 16))
+}
+
+// newTestClient returns a client of the room whose outgoing messages are buffered
+func newTestClient(r *Room, userID string) *Client {
+	client := &Client{
+		ID:   "client-" + userID,
+		Send: make(chan []byte, 256),
+		User: &types.User{ID: userID, Name: userID},
+	}
+	r.addClient(client, false)
+	return client
+}
+
+func mustMarshal(t *testing.T, message interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(message)
+	if err != nil {
+		t.Fatalf("failed to marshal message: %v", err)
+	}
+	return data
+}
+
+func TestCreateCharacterKeepsOnlyNameAndLook(t *testing.T) {
+	r := newTestRoom(t)
+	client := newTestClient(r, "alice")
+
+	handleCreateCharacterMessage(nil, client, mustMarshal(t, types.CreateCharacter{
+		BaseMessage: types.BaseMessage{Type: "create_character", MessageID: "1"},
+		Character: &types.Character{
+			Name:           "Alice",
+			Color:          "#ff0000",
+			Symbol:         "A",
+			ActionPoints:   99,
+			MovementPoints: 99,
+			Health:         1000,
+			Initiative:     1000,
+			Effects: []types.StatusEffect{
+				{Kind: "action_points", Value: 100, RemainingTurns: 10},
+			},
+			SpellCooldowns:     map[string]int{"1": -5},
+			SpellCastsThisTurn: map[string]int{"1": -5},
+		},
+	}))
+
+	player, exists := r.playerManager.GetPlayer("alice")
+	if !exists {
+		t.Fatal("player was not created")
+	}
+	want := game.NewCharacter("Alice", "#ff0000", "A")
+	got := player.Character
+	if got.Name != want.Name || got.Color != want.Color || got.Symbol != want.Symbol {
+		t.Errorf("look = %q %q %q, want %q %q %q", got.Name, got.Color, got.Symbol, want.Name, want.Color, want.Symbol)
+	}
+	if got.ActionPoints != want.ActionPoints || got.MovementPoints != want.MovementPoints ||
+		got.Health != want.Health || got.Initiative != want.Initiative || !got.IsAlive {
+		t.Errorf("stats = %+v, want the defaults %+v", got, want)
+	}
+	if len(got.Effects) != 0 {
+		t.Errorf("effects = %+v, want none", got.Effects)
+	}
+	if len(got.SpellCooldowns) != 0 || len(got.SpellCastsThisTurn) != 0 {
+		t.Errorf("spell counters = %v %v, want none", got.SpellCooldowns, got.SpellCastsThisTurn)
+	}
+}
+
+func TestCreateCharacterRequiresCharacter(t *testing.T) {
+	r := newTestRoom(t)
+	client := newTestClient(r, "alice")
+
+	handleCreateCharacterMessage(nil, client, []byte(`{"type": "create_character", "messageId": "1"}`))
+
+	if _, exists := r.playerManager.GetPlayer("alice"); exists {
+		t.Error("player created without a character")
+	}
+}
+
+// receivedTypes drains the messages queued for a client and returns them by type
+func receivedTypes(t *testing.T, client *Client) map[string][]map[string]interface{} {
+	t.Helper()
+	received := make(map[string][]map[string]interface{})
+	for {
+		select {
+		case data := <-client.Send:
+			var message map[string]interface{}
+			if err := json.Unmarshal(data, &message); err != nil {
+				t.Fatalf("invalid message %s: %v", data, err)
+			}
+			messageType, _ := message["type"].(string)
+			received[messageType] = append(received[messageType], message)
+		default:
+			return received
+		}
+	}
+}
+
+func TestReadyToStartReportsWhyTheGameCannotStart(t *testing.T) {
+	r := newTestRoom(t)
+	alice, bob := newTestClient(r, "alice"), newTestClient(r, "bob")
+	for _, client := range []*Client{alice, bob} {
+		handleCreateCharacterMessage(nil, client, mustMarshal(t, types.CreateCharacter{
+			BaseMessage: types.BaseMessage{Type: "create_character"},
+			Character:   &types.Character{Name: client.User.Name, Color: "red", Symbol: "X"},
+		}))
+		if err := r.playerManager.SetPlayerTeam(client.User.ID, "A"); err != nil {
+			t.Fatalf("SetPlayerTeam: %v", err)
+		}
+	}
+
+	handleReadyToStartMessage(nil, alice, []byte(`{"type": "ready_to_start", "messageId": "1"}`))
+	receivedTypes(t, bob)
+	handleReadyToStartMessage(nil, bob, []byte(`{"type": "ready_to_start", "messageId": "2"}`))
+
+	if status := r.gameManager.GetStatus(); status != game.PhaseLobby {
+		t.Fatalf("status = %q, want %q", status, game.PhaseLobby)
+	}
+	received := receivedTypes(t, bob)
+	errorMessages := received["error"]
+	if len(errorMessages) != 1 || errorMessages[0]["code"] != types.ReasonSingleTeam || errorMessages[0]["messageId"] != "2" {
+		t.Errorf("errors = %v, want one %s error", errorMessages, types.ReasonSingleTeam)
+	}
+	if len(received["game_state"]) == 0 {
+		t.Error("the ready state was not broadcast")
+	}
+}
@@ -0,0 +1,79 @@
+package websocket
+
+import (
+	"encoding/json"
+	"game-server/internal/types"
+	"log"
+	"strconv"
+)
+
+// handleGetReachableCellsMessage sends the cells the sender's character can walk to
+func handleGetReachableCellsMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
+
+	var baseMessage types.BaseMessage
+	if err := json.Unmarshal(message, &baseMessage); err != nil {
+		log.Printf("[Error] Invalid get reachable cells message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	cells, err := r.gameManager.MoveRange(c.User.ID)
+	if err != nil {
+		c.sendActionResult(baseMessage.MessageID, "get_reachable_cells", err)
+		return
+	}
+	c.sendMessage(types.ReachableCellsMessage{
+		Type:      "reachable_cells",
+		MessageID: baseMessage.MessageID,
+		Cells:     cells,
+	})
+}
+
+// handleGetSpellTargetsMessage sends the cells the sender's character can cast a
+// spell on, and what a cast on the requested target would hit
+func handleGetSpellTargetsMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
+
+	var request types.SpellTargetsRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		log.Printf("[Error] Invalid get spell targets message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	spellID := strconv.Itoa(request.SpellID)
+	targets, err := r.gameManager.SpellTargets(c.User.ID, spellID)
+	if err != nil {
+		c.sendActionResult(request.MessageID, "get_spell_targets", err)
+		return
+	}
+	response := types.SpellTargetsMessage{
+		Type:      "spell_targets",
+		MessageID: request.MessageID,
+		SpellID:   request.SpellID,
+		Targets:   targets,
+		Target:    request.Target,
+	}
+
+	if request.Target != nil && containsPosition(targets, *request.Target) {
+		area, hits, err := r.gameManager.PreviewSpell(c.User.ID, spellID, *request.Target)
+		if err != nil {
+			c.sendActionResult(request.MessageID, "get_spell_targets", err)
+			return
+		}
+		response.AffectedCells = area
+		response.Hits = hits
+	}
+	c.sendMessage(response)
+}
+
+// containsPosition reports whether a cell is in a list of cells
+func containsPosition(positions []types.Position, position types.Position) bool {
+	for _, candidate := range positions {
+		if candidate == position {
+			return true
+		}
+	}
+	return false
+}
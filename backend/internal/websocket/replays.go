@@ -0,0 +1,167 @@
+package websocket
+
+import (
+	"encoding/json"
+	"game-server/internal/game"
+	"game-server/internal/types"
+	"log"
+	"time"
+)
+
+const (
+	// Playback speed limits; 1 plays a fight at the pace it was played
+	defaultReplaySpeed = 1.0
+	minReplaySpeed     = 0.25
+	maxReplaySpeed     = 16.0
+	// maxReplayPause caps the wait between two frames, so that idle turns do not stall a replay
+	maxReplayPause = 3 * time.Second
+	// replayFrameWindow groups the events of a single action, recorded together, into one frame
+	replayFrameWindow = 50 * time.Millisecond
+)
+
+var errUnknownReplay = &game.RuleError{Code: types.ReasonUnknownReplay, Message: "unknown replay"}
+
+// replayStream is a replay a client is watching
+type replayStream struct {
+	gameID string
+	player *game.ReplayPlayer
+	speed  float64
+}
+
+// handleWatchReplayMessage streams the replay of a recorded game to the requesting
+// client, as game_state messages sent at the chosen speed. The client stays in its
+// room but gets none of its broadcasts until the replay ends.
+func handleWatchReplayMessage(h *Hub, c *Client, message []byte) {
+	var watchMessage types.WatchReplayMessage
+	if err := json.Unmarshal(message, &watchMessage); err != nil {
+		log.Printf("[Error] Invalid watch replay message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	if h.store == nil {
+		c.sendActionResult(watchMessage.MessageID, "watch_replay", errUnknownReplay)
+		return
+	}
+	replay, exists, err := h.store.GetReplay(watchMessage.GameID)
+	if err != nil {
+		log.Printf("[Error] Failed to read replay %s: %v", watchMessage.GameID, err)
+		c.sendActionResult(watchMessage.MessageID, "watch_replay", err)
+		return
+	}
+	if !exists {
+		c.sendActionResult(watchMessage.MessageID, "watch_replay", errUnknownReplay)
+		return
+	}
+	player, err := game.NewReplayPlayer(replay)
+	if err != nil {
+		log.Printf("[Error] Invalid replay %s: %v", watchMessage.GameID, err)
+		c.sendActionResult(watchMessage.MessageID, "watch_replay", err)
+		return
+	}
+
+	speed := watchMessage.Speed
+	if speed == 0 {
+		speed = defaultReplaySpeed
+	}
+	speed = min(max(speed, minReplaySpeed), maxReplaySpeed)
+
+	// Watching a replay replaces the one the client was watching
+	stream := &replayStream{gameID: replay.GameID, player: player, speed: speed}
+	c.replay = stream
+	c.sendActionResult(watchMessage.MessageID, "watch_replay", nil)
+	log.Printf("[Info] User %s watches the replay of game %s at speed %.2f", c.User.Name, replay.GameID, speed)
+
+	if h.sendReplayFrame(c, stream) {
+		h.scheduleReplayStep(c, stream, maxReplayPause)
+	}
+}
+
+// handleStopReplayMessage stops the replay the requesting client is watching
+func handleStopReplayMessage(h *Hub, c *Client, message []byte) {
+	var baseMessage types.BaseMessage
+	if err := json.Unmarshal(message, &baseMessage); err != nil {
+		log.Printf("[Error] Invalid stop replay message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	c.sendActionResult(baseMessage.MessageID, "stop_replay", nil)
+	h.endReplay(c)
+}
+
+// endReplay stops the replay a client is watching and sends it the current
+// state of its room again, which it missed during the replay
+func (h *Hub) endReplay(c *Client) {
+	c.replay = nil
+	if c.Room == nil {
+		return
+	}
+	if err := c.Room.sendSnapshot(c); err != nil {
+		log.Printf("[Error] Failed to send game state: %v", err)
+	}
+}
+
+// scheduleReplayStep plays the next frame of a replay once pause, as it was played, has passed
+func (h *Hub) scheduleReplayStep(c *Client, stream *replayStream, pause time.Duration) {
+	pause = time.Duration(float64(min(pause, maxReplayPause)) / stream.speed)
+	h.after(pause, func() {
+		h.playReplayStep(c, stream)
+	})
+}
+
+// playReplayStep applies the events of the next frame of a replay and sends the
+// resulting state. It does nothing once the client stopped watching the replay.
+func (h *Hub) playReplayStep(c *Client, stream *replayStream) {
+	if c.replay != stream {
+		return
+	}
+
+	// Apply the events recorded together, such as a spell cast and its damage
+	last, _ := stream.player.Next()
+	stream.player.Step()
+	for {
+		next, ok := stream.player.Next()
+		if !ok || next.Time.Sub(last.Time) > replayFrameWindow {
+			break
+		}
+		stream.player.Step()
+		last = next
+	}
+
+	if !h.sendReplayFrame(c, stream) {
+		return
+	}
+
+	next, ok := stream.player.Next()
+	if !ok {
+		c.sendMessage(types.ReplayFinishedMessage{
+			Type:   "replay_finished",
+			GameID: stream.gameID,
+		})
+		h.endReplay(c)
+		return
+	}
+	h.scheduleReplayStep(c, stream, next.Time.Sub(last.Time))
+}
+
+// sendReplayFrame sends the current state of a replay to the client watching it.
+// The replay stops if the client cannot receive it.
+func (h *Hub) sendReplayFrame(c *Client, stream *replayStream) bool {
+	frame, err := json.Marshal(map[string]interface{}{
+		"type":         "game_state",
+		"state":        stream.player.State(),
+		"replayGameId": stream.gameID,
+	})
+	if err != nil {
+		log.Printf("[Error] Failed to marshal replay frame: %v", err)
+		c.replay = nil
+		return false
+	}
+	if !c.trySend(frame) {
+		log.Printf("[Error] Failed to send replay frame to client %s", c.ID)
+		c.replay = nil
+		return false
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+package websocket
+
+import (
+	"testing"
+)
+
+func TestReplayWatcherSkipsRoomBroadcasts(t *testing.T) {
+	r := newTestRoom(t)
+	watcher, player := newTestClient(r, "alice"), newTestClient(r, "bob")
+	watcher.replay = &replayStream{gameID: "game"}
+
+	r.broadcastMessage([]byte(`{"type": "chat"}`))
+	if err := r.BroadcastGameState(); err != nil {
+		t.Fatalf("BroadcastGameState: %v", err)
+	}
+	if received := receivedTypes(t, watcher); len(received) != 0 {
+		t.Errorf("the client watching a replay received %v", received)
+	}
+	if received := receivedTypes(t, player); len(received["chat"]) != 1 || len(received["game_state"]) != 1 {
+		t.Errorf("the other client received %v, want the chat message and the game state", received)
+	}
+
+	// The room's state is sent again once the replay ends
+	(&Hub{}).endReplay(watcher)
+	if watcher.replay != nil {
+		t.Error("the replay did not stop")
+	}
+	if received := receivedTypes(t, watcher); len(received["game_state"]) != 1 {
+		t.Errorf("received %v after the replay, want the game state", received)
+	}
+}
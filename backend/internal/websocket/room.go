@@ -0,0 +1,274 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"game-server/internal/game"
+	"game-server/internal/storage"
+	"game-server/internal/types"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// resumeHistoryLength is the number of past messages replayed to a client resuming its session
+const resumeHistoryLength = 100
+
+// Room is a single fight: its own players, game state and connected clients.
+type Room struct {
+	ID      string
+	Name    string
+	MapID   string
+	Clients map[*Client]bool
+	// Clients watching the room without playing, a subset of Clients
+	spectators map[*Client]bool
+	// How late spectators receive the room's broadcasts, and the last game state they got
+	spectatorDelay time.Duration
+	spectatorState *stateSnapshot
+
+	// Last broadcast messages, replayed to resuming clients
+	recentMessages [][]byte
+
+	// Version of the game state, raised whenever it changes, and the last state sent
+	stateVersion int64
+	lastState    *stateSnapshot
+
+	// Game state
+	playerManager *game.PlayerManager
+	gameManager   *game.GameManager
+
+	// Scheduled work is sent to the hub so that it runs with the message handlers.
+	// Once the hub dropped the room, its scheduled work does nothing.
+	tasks  chan<- func()
+	closed bool
+
+	// Time limit of the turns
+	turnTimer *turnTimer
+
+	// Where finished games are recorded
+	store storage.Store
+
+	// Matchmaking mode of a ranked room, and the team of each matched player
+	mode          string
+	assignedTeams map[string]string
+
+	// Bots
+	botRng         *rand.Rand
+	botCount       int
+	botTurnActions int
+
+	// Concurrency control
+	mutex sync.Mutex
+}
+
+func NewRoom(id string, name string, spells *game.SpellCatalogue, board *game.Board, tasks chan<- func()) *Room {
+	return &Room{
+		ID:         id,
+		Name:       name,
+		MapID:      board.Layout().ID,
+		Clients:    make(map[*Client]bool),
+		spectators: make(map[*Client]bool),
+
+		playerManager: game.NewPlayerManager(),
+		gameManager:   game.NewGameManager(spells, board),
+
+		tasks:     tasks,
+		turnTimer: newTurnTimer(defaultTurnDuration, defaultTimeBank),
+		botRng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Info returns the public summary of the room shown in room lists
+func (r *Room) Info() types.RoomInfo {
+	r.mutex.Lock()
+	clientCount := len(r.Clients)
+	spectatorCount := len(r.spectators)
+	r.mutex.Unlock()
+
+	return types.RoomInfo{
+		ID:                    r.ID,
+		Name:                  r.Name,
+		PlayerCount:           len(r.playerManager.GetPlayers()),
+		ClientCount:           clientCount,
+		SpectatorCount:        spectatorCount,
+		GameStatus:            r.gameManager.GetStatus(),
+		MapID:                 r.MapID,
+		FriendlyFire:          r.gameManager.FriendlyFire(),
+		TurnSeconds:           int(r.turnTimer.turnDuration / time.Second),
+		TimeBankSeconds:       int(r.turnTimer.timeBank / time.Second),
+		SpectatorDelaySeconds: int(r.spectatorDelay / time.Second),
+		Mode:                  r.mode,
+	}
+}
+
+// IsEmpty reports whether no client is left in the room
+func (r *Room) IsEmpty() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.Clients) == 0
+}
+
+// close stops the room's turn countdown, bots and other scheduled work, once
+// the hub dropped the room
+func (r *Room) close() {
+	r.stopTurnTimer()
+	r.closed = true
+}
+
+// after runs task on the hub goroutine once delay has passed, unless the room
+// was closed meanwhile
+func (r *Room) after(delay time.Duration, task func()) *time.Timer {
+	return time.AfterFunc(delay, func() {
+		r.tasks <- func() {
+			if !r.closed {
+				task()
+			}
+		}
+	})
+}
+
+func (r *Room) addClient(client *Client, spectator bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Clients[client] = true
+	// Patches of another room's state do not apply to this one
+	client.syncedState = nil
+	if spectator {
+		r.spectators[client] = true
+	} else {
+		delete(r.spectators, client)
+	}
+	client.Room = r
+}
+
+func (r *Room) removeClient(client *Client) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.Clients, client)
+	delete(r.spectators, client)
+	if client.Room == r {
+		client.Room = nil
+	}
+}
+
+// currentGameState returns the state of the room's game as sent to clients
+func (r *Room) currentGameState() types.GameState {
+	currentState := r.gameManager.GetCurrentState()
+	// The lobby's players are managed by the room until the game starts
+	players := currentState.Players
+	if currentState.GameStatus == game.PhaseLobby {
+		players = r.playerManager.GetPlayers()
+	}
+	state := types.GameState{
+		MessageType:      "game_state",
+		Players:          players,
+		TurnNumber:       currentState.TurnNumber,
+		GameStatus:       currentState.GameStatus,
+		Spells:           currentState.Spells,
+		Timeline:         currentState.Timeline,
+		CurrentTurnIndex: currentState.CurrentTurnIndex,
+		Map:              currentState.Map,
+		FriendlyFire:     r.gameManager.FriendlyFire(),
+		UsingTimeBank:    r.turnTimer.usingBank,
+		TimeBanksMs:      r.timeBanksMs(),
+	}
+	if _, timed := r.turnTimeLeft(); timed {
+		state.TurnEndsAt = r.turnTimer.deadline.UnixMilli()
+	}
+	return state
+}
+
+// BroadcastGameState sends the current game state to every client in the room,
+// as a patch to the clients that asked for patches. Spectators receive it once
+// the room's spectator delay has passed.
+func (r *Room) BroadcastGameState() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	snapshot, err := r.snapshot()
+	if err != nil {
+		return err
+	}
+	log.Printf("[Debug] Broadcasting game state version %d to room %s", snapshot.version, r.ID)
+
+	patches := make(map[*stateSnapshot][]byte)
+	for client := range r.Clients {
+		if client.replay != nil || (r.spectatorDelay > 0 && r.spectators[client]) {
+			continue
+		}
+		if !r.sendState(client, snapshot, patches) {
+			client.closeSend()
+			delete(r.Clients, client)
+			delete(r.spectators, client)
+			log.Printf("[Error] Failed to send game state to client %s", client.ID)
+		}
+	}
+
+	if r.spectatorDelay > 0 {
+		r.after(r.spectatorDelay, func() {
+			r.sendStateToSpectators(snapshot)
+		})
+	}
+	return nil
+}
+
+// sendResumeState sends the recent room history followed by the current game
+// state to a single client, so that a reconnecting client can rebuild its view.
+// Spectators of a delayed room only get their delayed view back.
+func (r *Room) sendResumeState(client *Client) error {
+	if r.isSpectator(client) && r.spectatorDelay > 0 {
+		return r.sendSnapshot(client)
+	}
+
+	r.mutex.Lock()
+	history := append([][]byte(nil), r.recentMessages...)
+	r.mutex.Unlock()
+	for _, message := range history {
+		if !client.trySend(message) {
+			return fmt.Errorf("failed to send history to client %s", client.ID)
+		}
+	}
+
+	return r.sendSnapshot(client)
+}
+
+// broadcastMessage sends a message to every client in the room. Spectators
+// receive it once the room's spectator delay has passed.
+func (r *Room) broadcastMessage(message []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var prettyJSON bytes.Buffer
+	if err := json.Indent(&prettyJSON, message, "", "  "); err != nil {
+		log.Printf("[Debug] Broadcasting message to room %s (raw): %s", r.ID, string(message))
+	} else {
+		log.Printf("[Debug] Broadcasting message to room %s:\n%s", r.ID, prettyJSON.String())
+	}
+	// Keep the message for clients resuming their session
+	r.recentMessages = append(r.recentMessages, message)
+	if len(r.recentMessages) > resumeHistoryLength {
+		r.recentMessages = r.recentMessages[len(r.recentMessages)-resumeHistoryLength:]
+	}
+
+	for client := range r.Clients {
+		if client.replay != nil || (r.spectatorDelay > 0 && r.spectators[client]) {
+			continue
+		}
+		if client.trySend(message) {
+			log.Printf("[Debug] Sent message to client %s", client.ID)
+		} else {
+			client.closeSend()
+			delete(r.Clients, client)
+			delete(r.spectators, client)
+			log.Printf("[Error] Failed to send to client %s", client.ID)
+		}
+	}
+
+	if r.spectatorDelay > 0 {
+		r.after(r.spectatorDelay, func() {
+			r.sendToSpectators(message)
+		})
+	}
+}
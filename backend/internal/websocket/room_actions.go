@@ -0,0 +1,330 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"game-server/internal/game"
+	"game-server/internal/types"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// The fight actions below apply a player's action to the room and broadcast the
+// outcome. They return a *game.RuleError when the action is refused, so that the
+// caller can report the reason to the player.
+
+// checkCurrentTurn makes sure it is the player's turn to act, with a living character
+func (r *Room) checkCurrentTurn(userID string) error {
+	player, exists := r.playerManager.GetPlayer(userID)
+	if !exists || player.Character == nil {
+		return game.ErrPlayerNotFound
+	}
+	if currentUserID, ok := r.gameManager.GetCurrentTurnPlayer(); !ok || currentUserID != userID {
+		return game.ErrNotYourTurn
+	}
+	if !r.isAlive(userID) {
+		return game.ErrCharacterDead
+	}
+	return nil
+}
+
+// moveCharacter moves a player's character to target along the shortest free path
+func (r *Room) moveCharacter(userID string, target types.Position) error {
+	if err := r.checkCurrentTurn(userID); err != nil {
+		return err
+	}
+
+	// Check the move against the board, the other characters and the remaining MP
+	path, err := r.gameManager.FindMovePath(userID, target)
+	if err != nil {
+		return err
+	}
+	from := *r.gameManager.GetCurrentState().Players[userID].Character.Position
+
+	// Walk the path, paying one MP per cell
+	if err := r.gameManager.MoveCharacter(userID, path); err != nil {
+		return fmt.Errorf("failed to move character: %w", err)
+	}
+
+	r.broadcastEvent(types.CharacterMovedMessage{
+		Type:   "character_moved",
+		UserID: userID,
+		From:   from,
+		Path:   path,
+	})
+	r.broadcastOutcome()
+	return nil
+}
+
+// castSpell casts a spell from a player's character on the target cell.
+// 1. Check the player has enough AP, the spell is available and the target is valid.
+// 2. Charge the AP cost and apply damage or effects of the spell to target positions.
+// 3. Broadcast the updated game state to all players.
+func (r *Room) castSpell(userID string, spellID int, target types.Position) error {
+	if err := r.checkCurrentTurn(userID); err != nil {
+		return err
+	}
+
+	spellIDStr := strconv.Itoa(spellID)
+
+	// Get the casting player's current AP
+	currentPlayer, exists := r.gameManager.GetCurrentState().Players[userID]
+	if !exists || currentPlayer.Character == nil {
+		return game.ErrPlayerNotFound
+	}
+	currentAP := currentPlayer.Character.ActionPoints
+
+	// Compute the spell cost
+	spellCost, err := r.gameManager.GetSpellCost(spellIDStr)
+	if err != nil {
+		return err
+	}
+
+	// Check if player has enough AP
+	if currentAP < spellCost {
+		return game.ErrNotEnoughAP
+	}
+
+	// Check the spell's cooldown and casts-per-turn limit
+	if err := r.gameManager.CheckSpellUsage(userID, spellIDStr); err != nil {
+		return err
+	}
+
+	// Check range, line of sight and casting rules of the spell
+	if err := r.gameManager.ValidateSpellTarget(userID, spellIDStr, target); err != nil {
+		return err
+	}
+
+	// The cells the spell covers, hit or not
+	area, err := r.gameManager.SpellArea(userID, spellIDStr, target)
+	if err != nil {
+		return err
+	}
+
+	// Charge the cast and apply damage or effects of the spell to target positions
+	hits, critical, err := r.gameManager.CastSpell(userID, spellIDStr, target)
+	if err != nil {
+		return fmt.Errorf("failed to cast spell: %w", err)
+	}
+
+	// Tell every client what the cast did, critical hits and deaths included
+	r.broadcastEvent(types.SpellCastMessage{
+		Type:           "spell_cast",
+		CasterID:       userID,
+		SpellID:        spellID,
+		TargetPosition: target,
+		AffectedCells:  area,
+		Critical:       critical,
+		Hits:           hits,
+	})
+	for _, hit := range hits {
+		if hit.IsDead {
+			r.broadcastEvent(types.CharacterDiedMessage{
+				Type:     "character_died",
+				UserID:   hit.UserID,
+				KillerID: userID,
+				SpellID:  spellID,
+			})
+		}
+	}
+
+	r.broadcastOutcome()
+	return nil
+}
+
+// endTurn ends a player's turn and hands it to the next living character of the timeline.
+// It runs when the player sends end_turn or when its turn times out.
+// 1. Reset the character's spell casts and count its status effects down
+// 2. Advance the timeline, starting a new round when it wraps around
+// 3. Start the turn of the next player, skipping it if its status effects kill it
+// 4. Broadcast the updated state
+func (r *Room) endTurn(userID string) error {
+	if err := r.checkCurrentTurn(userID); err != nil {
+		return err
+	}
+	return r.passTurn(userID)
+}
+
+// passTurn ends the current turn of a player, whether or not its character
+// survived it, and hands the turn to the next living character
+func (r *Room) passTurn(userID string) error {
+	// Stop the turn countdown, charging the time bank
+	r.stopTurnTimer()
+
+	// Reset the casts-per-turn counters and count the status effects down
+	if err := r.gameManager.EndTurn(userID); err != nil {
+		return fmt.Errorf("failed to end turn: %w", err)
+	}
+
+	for {
+		// Start the turn of the next character in the timeline
+		nextUserID, err := r.gameManager.AdvanceTurn()
+		if err != nil {
+			return fmt.Errorf("failed to advance turn: %w", err)
+		}
+
+		if err := r.startTurn(nextUserID); err != nil {
+			return err
+		}
+
+		// A poisoned character can die at the start of its turn
+		if _, gameOver := r.gameManager.CheckGameOver(); gameOver {
+			break
+		}
+		if player, exists := r.gameManager.GetCurrentState().Players[nextUserID]; !exists || player.Character == nil || player.Character.IsAlive {
+			break
+		}
+		log.Printf("[Game] Player %s died at the start of its turn", nextUserID)
+	}
+
+	r.broadcastOutcome()
+	return nil
+}
+
+// startTurn runs once the game manager gave the turn to a player: it starts the
+// turn countdown, announces the turn and applies the status effects of its
+// character. A bot then starts playing.
+func (r *Room) startTurn(userID string) error {
+	r.startTurnTimer(userID)
+	turnStarted := types.TurnStartedMessage{
+		Type:       "turn_started",
+		UserID:     userID,
+		TurnNumber: r.gameManager.GetTurnNumber(),
+	}
+	if _, timed := r.turnTimeLeft(); timed {
+		turnStarted.TurnEndsAt = r.turnTimer.deadline.UnixMilli()
+	}
+	r.broadcastEvent(turnStarted)
+
+	// Poison and AP/MP changes apply on top of the restored points
+	wasAlive := r.isAlive(userID)
+	if err := r.gameManager.ApplyTurnStartEffects(userID); err != nil {
+		return fmt.Errorf("failed to apply status effects: %w", err)
+	}
+	if wasAlive && !r.isAlive(userID) {
+		killerID, _ := r.gameManager.Killer(userID)
+		r.broadcastEvent(types.CharacterDiedMessage{
+			Type:     "character_died",
+			UserID:   userID,
+			KillerID: killerID,
+		})
+	}
+
+	// Bots play their turn on their own
+	if player, exists := r.playerManager.GetPlayer(userID); exists && player.IsBot {
+		r.botTurnActions = 0
+		r.scheduleBotStep(userID)
+	}
+	return nil
+}
+
+// isAlive reports whether a player's character is alive in the fight
+func (r *Room) isAlive(userID string) bool {
+	player, exists := r.gameManager.GetCurrentState().Players[userID]
+	return exists && player.Character != nil && player.Character.IsAlive
+}
+
+// isFighting reports whether a player's character is alive in the room's running game
+func (r *Room) isFighting(userID string) bool {
+	status := r.gameManager.GetStatus()
+	return (status == game.PhasePlacement || status == game.PhaseFighting) && r.isAlive(userID)
+}
+
+// broadcastEvent tells every client in the room what just happened in the fight
+func (r *Room) broadcastEvent(event interface{}) {
+	message, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("[Error] Failed to marshal %T: %v", event, err)
+		return
+	}
+	r.broadcastMessage(message)
+}
+
+// recordGame stores the result and the replay of the fight that just ended
+func (r *Room) recordGame(winningTeam string) {
+	if r.store == nil {
+		return
+	}
+
+	stats := r.gameManager.FightStats()
+	record := types.GameRecord{
+		ID:          generateUniqueID(),
+		RoomID:      r.ID,
+		MapID:       r.MapID,
+		StartedAt:   r.gameManager.FightStartedAt(),
+		FinishedAt:  time.Now(),
+		Turns:       r.gameManager.GetTurnNumber(),
+		WinningTeam: winningTeam,
+	}
+	for userID, player := range r.gameManager.GetCurrentState().Players {
+		participant := types.Participant{
+			UserID:     userID,
+			UserName:   player.UserName,
+			Team:       player.Team,
+			IsBot:      player.IsBot,
+			Won:        winningTeam != "" && player.Team == winningTeam,
+			FightStats: stats[userID],
+		}
+		if player.Character != nil {
+			participant.CharacterName = player.Character.Name
+			participant.Survived = player.Character.IsAlive
+		}
+		record.Participants = append(record.Participants, participant)
+	}
+	sort.Slice(record.Participants, func(i, j int) bool {
+		return record.Participants[i].UserID < record.Participants[j].UserID
+	})
+
+	if err := r.store.SaveGame(record); err != nil {
+		log.Printf("[Error] Failed to record game %s: %v", record.ID, err)
+		return
+	}
+	log.Printf("[Game] Recorded game %s", record.ID)
+
+	replay, err := r.gameManager.Replay(record.ID)
+	if err != nil {
+		log.Printf("[Error] Failed to export the replay of game %s: %v", record.ID, err)
+		return
+	}
+	if err := r.store.SaveReplay(replay); err != nil {
+		log.Printf("[Error] Failed to record the replay of game %s: %v", record.ID, err)
+	}
+}
+
+// broadcastOutcome broadcasts game_over if the last action ended the fight,
+// passes the turn on if it killed the character playing it, then broadcasts
+// the updated game state
+func (r *Room) broadcastOutcome() {
+	// Check for game over condition
+	winningTeam, gameOver := r.gameManager.CheckGameOver()
+	if gameOver {
+		log.Printf("[Game Over] Winning team: %s", winningTeam)
+		r.stopTurnTimer()
+		if err := r.gameManager.SetGameStatus(game.PhaseFinished); err != nil {
+			log.Printf("[Error] Failed to finish the game: %v", err)
+		}
+		r.recordGame(winningTeam)
+		r.updateRatings(winningTeam)
+		gameOverMessage, _ := json.Marshal(types.GameOverMessage{
+			Type:        "game_over",
+			WinningTeam: winningTeam,
+			Members:     game.TeamMembers(winningTeam, r.gameManager.GetCurrentState().Players),
+		})
+		r.broadcastMessage(gameOverMessage)
+	} else if currentUserID, ok := r.gameManager.GetCurrentTurnPlayer(); ok && !r.isAlive(currentUserID) {
+		// A character killed during its own turn, by its own spell for instance,
+		// hands the turn on. Passing the turn broadcasts the outcome again.
+		log.Printf("[Game] Player %s died during its turn", currentUserID)
+		if err := r.passTurn(currentUserID); err != nil {
+			log.Printf("[Error] Failed to pass the turn of player %s: %v", currentUserID, err)
+		}
+		return
+	}
+
+	// Broadcast the updated state
+	if err := r.BroadcastGameState(); err != nil {
+		log.Printf("[Error] Failed to broadcast game state: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package websocket
+
+import (
+	"game-server/internal/game"
+	"game-server/internal/game/gametest"
+	"game-server/internal/types"
+	"strconv"
+	"testing"
+)
+
+// startTestFight creates a character for each client in its team, gets every
+// client ready and places the characters, the last one through its message
+func startTestFight(t *testing.T, r *Room, clients []*Client, teams []string) {
+	t.Helper()
+	for i, client := range clients {
+		handleCreateCharacterMessage(nil, client, mustMarshal(t, types.CreateCharacter{
+			BaseMessage: types.BaseMessage{Type: "create_character"},
+			Character:   &types.Character{Name: client.User.Name, Color: "red", Symbol: "X"},
+		}))
+		if err := r.playerManager.SetPlayerTeam(client.User.ID, teams[i]); err != nil {
+			t.Fatalf("SetPlayerTeam: %v", err)
+		}
+	}
+	for _, client := range clients {
+		handleReadyToStartMessage(nil, client, []byte(`{"type": "ready_to_start"}`))
+	}
+
+	for _, client := range clients[:len(clients)-1] {
+		gametest.Place(t, r.gameManager, client.User.ID)
+	}
+	last := clients[len(clients)-1]
+	handleCharacterPositionedMessage(nil, last, mustMarshal(t, types.CharacterPositionedMessage{
+		BaseMessage: types.BaseMessage{Type: "character_positioned"},
+		Position:    gametest.Place(t, r.gameManager, last.User.ID),
+	}))
+	if status := r.gameManager.GetStatus(); status != game.PhaseFighting {
+		t.Fatalf("status = %q, want %q", status, game.PhaseFighting)
+	}
+}
+
+func TestTurnPassesWhenCurrentCharacterDies(t *testing.T) {
+	r := newTestRoom(t)
+	clients := []*Client{newTestClient(r, "alice"), newTestClient(r, "bob"), newTestClient(r, "carol")}
+	startTestFight(t, r, clients, []string{"A", "A", "B"})
+
+	// A character of the team of two kills itself with friendly fire
+	current, _ := r.gameManager.GetCurrentTurnPlayer()
+	if current == "carol" {
+		if err := r.endTurn(current); err != nil {
+			t.Fatalf("endTurn: %v", err)
+		}
+		current, _ = r.gameManager.GetCurrentTurnPlayer()
+	}
+	position := *r.gameManager.GetCurrentState().Players[current].Character.Position
+	if err := r.castSpell(current, 1, position); err != nil {
+		t.Fatalf("castSpell: %v", err)
+	}
+	if r.isAlive(current) {
+		t.Fatal("the caster survived its own spell")
+	}
+	if r.gameManager.GetStatus() != game.PhaseFighting {
+		t.Fatal("the fight ended with both teams alive")
+	}
+
+	next, _ := r.gameManager.GetCurrentTurnPlayer()
+	if next == current || !r.isAlive(next) {
+		t.Errorf("turn went to %s, want a living character other than %s", next, current)
+	}
+	if err := r.endTurn(current); err != game.ErrNotYourTurn {
+		t.Errorf("endTurn error = %v, want %v", err, game.ErrNotYourTurn)
+	}
+	if err := r.moveCharacter(current, types.Position{X: position.X, Y: 1}); err == nil {
+		t.Error("a dead character moved")
+	}
+}
+
+func TestGameOverListsTheWinningFighters(t *testing.T) {
+	r := newTestRoom(t)
+	clients := []*Client{newTestClient(r, "alice"), newTestClient(r, "bob"), newTestClient(r, "carol")}
+	startTestFight(t, r, clients, []string{"A", "B", "A"})
+
+	// The lobby no longer matches the fight once it has started
+	r.playerManager.UpdatePlayer("dave", types.Player{UserID: "dave", UserName: "dave", Team: "A"})
+
+	// bob kills himself with friendly fire, and team A wins
+	for current, _ := r.gameManager.GetCurrentTurnPlayer(); current != "bob"; current, _ = r.gameManager.GetCurrentTurnPlayer() {
+		if err := r.endTurn(current); err != nil {
+			t.Fatalf("endTurn: %v", err)
+		}
+	}
+	receivedTypes(t, clients[0])
+	position := *r.gameManager.GetCurrentState().Players["bob"].Character.Position
+	if err := r.castSpell("bob", 1, position); err != nil {
+		t.Fatalf("castSpell: %v", err)
+	}
+
+	gameOver := receivedTypes(t, clients[0])["game_over"]
+	if len(gameOver) != 1 {
+		t.Fatalf("received %d game_over messages, want 1", len(gameOver))
+	}
+	var members []string
+	for _, member := range gameOver[0]["members"].([]interface{}) {
+		members = append(members, member.(map[string]interface{})["userId"].(string))
+	}
+	if len(members) != 2 || members[0] != "alice" || members[1] != "carol" {
+		t.Errorf("winning members = %v, want alice and carol", members)
+	}
+}
+
+// A cheap short range spell and a spell no character has the AP to cast
+const refusedActionSpells = `[
+	{"id": 1, "name": "Jab", "APCost": 1, "range": 1, "damage": 1, "areaOfEffect": "none", "type": "Melee"},
+	{"id": 2, "name": "Meteor", "APCost": 99, "range": 9, "damage": 1, "areaOfEffect": "none", "type": "Fire"}
+]`
+
+func TestRefusedActionsReportTheirReason(t *testing.T) {
+	h := newTestHub()
+	r := newTestRoomWithSpells(t, refusedActionSpells)
+	h.rooms[r.ID] = r
+	clients := []*Client{newTestClient(r, "alice"), newTestClient(r, "bob")}
+	startTestFight(t, r, clients, []string{"A", "B"})
+
+	current, waiting := clients[0], clients[1]
+	if userID, _ := r.gameManager.GetCurrentTurnPlayer(); userID != current.User.ID {
+		current, waiting = waiting, current
+	}
+	position := *r.gameManager.GetCurrentState().Players[current.User.ID].Character.Position
+	// The placement row of the other team is two cells away, out of the jab's range
+	farCell := types.Position{X: position.X, Y: 2 - position.Y}
+	receivedTypes(t, current)
+	receivedTypes(t, waiting)
+
+	tests := []struct {
+		name    string
+		client  *Client
+		spellID int
+		target  types.Position
+		code    string
+	}{
+		{"not your turn", waiting, 1, position, types.ReasonNotYourTurn},
+		{"not enough AP", current, 2, position, types.ReasonNotEnoughAP},
+		{"out of range", current, 1, farCell, types.ReasonOutOfRange},
+	}
+	for i, test := range tests {
+		messageID := strconv.Itoa(i + 1)
+		h.dispatch(test.client, mustMarshal(t, map[string]interface{}{
+			"type":           "cast_spell",
+			"messageId":      messageID,
+			"spellId":        test.spellID,
+			"targetPosition": test.target,
+		}))
+
+		results := receivedTypes(t, test.client)["action_result"]
+		if len(results) != 1 {
+			t.Fatalf("%s: results = %v, want one", test.name, results)
+		}
+		result := results[0]
+		if result["messageId"] != messageID || result["action"] != "cast_spell" || result["success"] != false || result["code"] != test.code {
+			t.Errorf("%s: result = %v, want cast_spell refused with %s", test.name, result, test.code)
+		}
+	}
+}
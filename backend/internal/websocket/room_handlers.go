@@ -0,0 +1,155 @@
+package websocket
+
+import (
+	"encoding/json"
+	"game-server/internal/game"
+	"game-server/internal/types"
+	"log"
+	"time"
+)
+
+var (
+	errUnknownRoom = &game.RuleError{Code: types.ReasonUnknownRoom, Message: "unknown room"}
+	errInFight     = &game.RuleError{Code: types.ReasonInFight, Message: "finish or leave the running fight first"}
+)
+
+// handleListRoomsMessage sends the list of rooms to the requesting client
+func handleListRoomsMessage(h *Hub, c *Client, message []byte) {
+	c.sendMessage(types.RoomListMessage{
+		Type:  "rooms_list",
+		Rooms: h.ListRooms(),
+	})
+}
+
+// handleCreateRoomMessage creates a new room and moves the requesting client into it
+func handleCreateRoomMessage(h *Hub, c *Client, message []byte) {
+	var roomMessage types.RoomMessage
+	if err := json.Unmarshal(message, &roomMessage); err != nil {
+		log.Printf("[Error] Invalid create room message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	// A player alive in a running fight stays in it
+	if _, fighting := h.fightingRoom(c.User.ID); fighting {
+		c.sendActionResult(roomMessage.MessageID, "create_room", errInFight)
+		return
+	}
+
+	settings := RoomSettings{
+		MapID:          roomMessage.MapID,
+		FriendlyFire:   true,
+		TurnDuration:   time.Duration(roomMessage.TurnSeconds) * time.Second,
+		TimeBank:       time.Duration(roomMessage.TimeBankSeconds) * time.Second,
+		SpectatorDelay: time.Duration(roomMessage.SpectatorDelaySeconds) * time.Second,
+	}
+	if roomMessage.FriendlyFire != nil {
+		settings.FriendlyFire = *roomMessage.FriendlyFire
+	}
+	room, err := h.CreateRoom(roomMessage.Name, settings)
+	if err != nil {
+		log.Printf("[Error] User %s tried to create a room on unknown map %s", c.User.Name, roomMessage.MapID)
+		c.sendActionResult(roomMessage.MessageID, "create_room", err)
+		return
+	}
+	moveClientToRoom(h, c, room, roomMessage.Spectator)
+}
+
+// handleJoinRoomMessage moves the requesting client into an existing room, as a
+// player or as a spectator. Joining its own room switches the client's role.
+func handleJoinRoomMessage(h *Hub, c *Client, message []byte) {
+	var roomMessage types.RoomMessage
+	if err := json.Unmarshal(message, &roomMessage); err != nil {
+		log.Printf("[Error] Invalid join room message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	room, exists := h.GetRoom(roomMessage.RoomID)
+	if !exists {
+		log.Printf("[Error] User %s tried to join unknown room %s", c.User.Name, roomMessage.RoomID)
+		c.sendActionResult(roomMessage.MessageID, "join_room", errUnknownRoom)
+		return
+	}
+	if fightRoom, fighting := h.fightingRoom(c.User.ID); fighting && fightRoom != room {
+		c.sendActionResult(roomMessage.MessageID, "join_room", errInFight)
+		return
+	}
+
+	spectator := roomMessage.Spectator || room.mustSpectate(c.User.ID)
+	moveClientToRoom(h, c, room, spectator)
+	c.sendActionResult(roomMessage.MessageID, "join_room", nil)
+}
+
+// handleLeaveRoomMessage removes the requesting client from its room.
+// A character that has not started fighting yet is removed with it, and a
+// character alive in a running fight cannot leave it.
+func handleLeaveRoomMessage(h *Hub, c *Client, message []byte) {
+	var baseMessage types.BaseMessage
+	if err := json.Unmarshal(message, &baseMessage); err != nil {
+		log.Printf("[Error] Invalid leave room message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	r := c.Room
+	if r == nil {
+		return
+	}
+	if r.isFighting(c.User.ID) {
+		c.sendActionResult(baseMessage.MessageID, "leave_room", errInFight)
+		return
+	}
+
+	removePlayerBeforeFight(r, c.User.ID)
+	h.LeaveRoom(c)
+	h.sessions.SetRoom(c.Session, "", false)
+	c.sendActionResult(baseMessage.MessageID, "leave_room", nil)
+
+	if err := r.BroadcastGameState(); err != nil {
+		log.Printf("[Error] Failed to broadcast game state: %v", err)
+	}
+}
+
+// moveClientToRoom switches a client to a room as a player or a spectator, then
+// sends it the room details and the room's current game state. A client that
+// becomes a spectator gives its character up if the game has not started.
+func moveClientToRoom(h *Hub, c *Client, room *Room, spectator bool) {
+	if previous := c.Room; previous != nil && previous != room {
+		removePlayerBeforeFight(previous, c.User.ID)
+		h.LeaveRoom(c)
+		if err := previous.BroadcastGameState(); err != nil {
+			log.Printf("[Error] Failed to broadcast game state: %v", err)
+		}
+	}
+	if spectator {
+		removePlayerBeforeFight(room, c.User.ID)
+	}
+
+	// The room's game states would mix with the frames of a replay
+	c.replay = nil
+	h.JoinRoom(c, room, spectator)
+	c.sendMessage(types.RoomJoinedMessage{
+		Type:      "room_joined",
+		Room:      room.Info(),
+		Spectator: spectator,
+	})
+	if spectator && room.spectatorDelay > 0 {
+		if err := room.sendSnapshot(c); err != nil {
+			log.Printf("[Error] Failed to send game state: %v", err)
+		}
+	}
+
+	if err := room.BroadcastGameState(); err != nil {
+		log.Printf("[Error] Failed to broadcast game state: %v", err)
+	}
+}
+
+// removePlayerBeforeFight removes a user's character from a room whose game has
+// not started, so that it does not block the other players from starting
+func removePlayerBeforeFight(r *Room, userID string) {
+	if r.gameManager.GetStatus() != game.PhaseLobby {
+		return
+	}
+	r.playerManager.RemovePlayer(userID)
+}
@@ -0,0 +1,126 @@
+package websocket
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"game-server/internal/types"
+	"sync"
+	"time"
+)
+
+// sessionTTL is how long a session can be resumed after its last connection
+const sessionTTL = 24 * time.Hour
+
+// Session ties a resumable token to a user, so that a client reconnecting with the
+// token gets back the same identity, room and character.
+type Session struct {
+	Token  string
+	User   *types.User
+	RoomID string
+	// Whether the user watches the room as a spectator
+	Spectator bool
+	LastSeen  time.Time
+}
+
+// expired reports whether the session can no longer be resumed
+func (session *Session) expired(now time.Time) bool {
+	return now.Sub(session.LastSeen) > sessionTTL
+}
+
+type SessionStore struct {
+	sessions map[string]*Session
+	mutex    sync.Mutex
+}
+
+func NewSessionStore() *SessionStore {
+	return &SessionStore{
+		sessions: make(map[string]*Session),
+	}
+}
+
+func generateSessionToken() string {
+	bytes := make([]byte, 32)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// Create starts a new session for a user
+func (s *SessionStore) Create(user *types.User) *Session {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session := &Session{
+		Token:    generateSessionToken(),
+		User:     user,
+		LastSeen: time.Now(),
+	}
+	s.sessions[session.Token] = session
+	return session
+}
+
+// Resume returns the session matching a token if it has not expired
+func (s *SessionStore) Resume(token string) (*Session, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session, ok := s.sessions[token]
+	if !ok {
+		return nil, false
+	}
+	if session.expired(time.Now()) {
+		delete(s.sessions, token)
+		return nil, false
+	}
+	session.LastSeen = time.Now()
+	return session, true
+}
+
+// Touch records that the session was used just now
+func (s *SessionStore) Touch(session *Session) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	session.LastSeen = time.Now()
+}
+
+// SetRoom records the room the session's user is in, and whether as a spectator
+func (s *SessionStore) SetRoom(session *Session, roomID string, spectator bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	session.RoomID = roomID
+	session.Spectator = spectator
+}
+
+// GetRoom returns the room the session's user was last in, and whether as a spectator
+func (s *SessionStore) GetRoom(session *Session) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return session.RoomID, session.Spectator
+}
+
+// InRoom reports whether a user has a session that can still resume into a room
+func (s *SessionStore) InRoom(userID string, roomID string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, session := range s.sessions {
+		if session.User.ID == userID && session.RoomID == roomID && !session.expired(time.Now()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sweep forgets the sessions that expired, and returns how many there were
+func (s *SessionStore) Sweep(now time.Time) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	swept := 0
+	for token, session := range s.sessions {
+		if session.expired(now) {
+			delete(s.sessions, token)
+			swept++
+		}
+	}
+	return swept
+}
@@ -0,0 +1,24 @@
+package websocket
+
+import (
+	"game-server/internal/types"
+	"testing"
+	"time"
+)
+
+func TestSweepForgetsExpiredSessions(t *testing.T) {
+	s := NewSessionStore()
+	stale := s.Create(&types.User{ID: "alice"})
+	fresh := s.Create(&types.User{ID: "bob"})
+	stale.LastSeen = time.Now().Add(-sessionTTL - time.Minute)
+
+	if swept := s.Sweep(time.Now()); swept != 1 {
+		t.Errorf("swept %d sessions, want 1", swept)
+	}
+	if _, exists := s.sessions[stale.Token]; exists {
+		t.Error("the expired session was kept")
+	}
+	if _, ok := s.Resume(fresh.Token); !ok {
+		t.Error("the live session was forgotten")
+	}
+}
@@ -0,0 +1,78 @@
+package websocket
+
+import (
+	"game-server/internal/game"
+	"game-server/internal/types"
+	"log"
+)
+
+var errSpectator = &game.RuleError{Code: types.ReasonSpectator, Message: "spectators cannot act in the game"}
+
+// Message types a spectator can send: chat, state synchronisation and the room
+// and replay messages. Every other message is an action on the game and is refused.
+var spectatorMessageTypes = map[string]bool{
+	"chat":         true,
+	"disconnect":   true,
+	"sync_mode":    true,
+	"sync_request": true,
+}
+
+// canSpectatorSend reports whether a spectator may send a message type
+func canSpectatorSend(messageType string) bool {
+	return spectatorMessageTypes[messageType] || roomlessMessageTypes[messageType]
+}
+
+// isSpectator reports whether a client watches the room as a spectator
+func (r *Room) isSpectator(client *Client) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.spectators[client]
+}
+
+// mustSpectate reports whether a user can only watch the room: a ranked room is
+// played by its matched players only, and a game past the lobby by the players
+// who have a character in it
+func (r *Room) mustSpectate(userID string) bool {
+	if _, assigned := r.assignedTeams[userID]; r.mode != "" && !assigned {
+		return true
+	}
+	return r.gameManager.GetStatus() != game.PhaseLobby && !r.gameManager.HasCharacter(userID)
+}
+
+// sendToSpectators sends a delayed broadcast to the room's spectators
+func (r *Room) sendToSpectators(message []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for client := range r.spectators {
+		if client.replay != nil {
+			continue
+		}
+		if !client.trySend(message) {
+			client.closeSend()
+			delete(r.Clients, client)
+			delete(r.spectators, client)
+			log.Printf("[Error] Failed to send to spectator %s", client.ID)
+		}
+	}
+}
+
+// sendStateToSpectators sends a delayed game state to the room's spectators
+func (r *Room) sendStateToSpectators(snapshot *stateSnapshot) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.spectatorState = snapshot
+	patches := make(map[*stateSnapshot][]byte)
+	for client := range r.spectators {
+		if client.replay != nil {
+			continue
+		}
+		if !r.sendState(client, snapshot, patches) {
+			client.closeSend()
+			delete(r.Clients, client)
+			delete(r.spectators, client)
+			log.Printf("[Error] Failed to send game state to spectator %s", client.ID)
+		}
+	}
+}
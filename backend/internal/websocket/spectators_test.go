@@ -0,0 +1,72 @@
+package websocket
+
+import (
+	"game-server/internal/game"
+	"game-server/internal/types"
+	"testing"
+	"time"
+)
+
+func TestMustSpectate(t *testing.T) {
+	r := newTestRoom(t)
+	if r.mustSpectate("carol") {
+		t.Error("a user must not be forced to spectate the lobby")
+	}
+
+	err := r.gameManager.StartGame(map[string]types.Player{
+		"alice": {UserID: "alice", Team: "A", IsReady: true, Character: game.NewCharacter("Alice", "red", "A")},
+		"bob":   {UserID: "bob", Team: "B", IsReady: true, Character: game.NewCharacter("Bob", "blue", "B")},
+	})
+	if err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+	if r.mustSpectate("alice") {
+		t.Error("a player of the game must be able to join it as a player")
+	}
+	if !r.mustSpectate("carol") {
+		t.Error("a user without a character must spectate a running game")
+	}
+
+	r.mode = "duel"
+	r.assignedTeams = map[string]string{"alice": "A"}
+	if !r.mustSpectate("bob") {
+		t.Error("a user not matched into a ranked room must spectate it")
+	}
+}
+
+func TestSpectatorsReceiveBroadcastsAfterTheDelay(t *testing.T) {
+	tasks := make(chan func(), 16)
+	r := newTestRoom(t)
+	r.tasks = tasks
+	r.spectatorDelay = 20 * time.Millisecond
+	alice := newTestClient(r, "alice")
+	carol := &Client{ID: "client-carol", Send: make(chan []byte, 256), User: &types.User{ID: "carol", Name: "carol"}}
+	r.addClient(carol, true)
+
+	start := time.Now()
+	r.broadcastMessage([]byte(`{"type": "chat"}`))
+	if err := r.BroadcastGameState(); err != nil {
+		t.Fatalf("BroadcastGameState: %v", err)
+	}
+
+	received := receivedTypes(t, alice)
+	if len(received["chat"]) != 1 || len(received["game_state"]) != 1 {
+		t.Errorf("player received %v, want the chat message and the game state at once", received)
+	}
+	if received := receivedTypes(t, carol); len(received) != 0 {
+		t.Errorf("spectator received %v before the delay", received)
+	}
+
+	runNextTask(t, tasks)
+	runNextTask(t, tasks)
+	if elapsed := time.Since(start); elapsed < r.spectatorDelay {
+		t.Errorf("spectator broadcasts ran after %v, before the %v delay", elapsed, r.spectatorDelay)
+	}
+	received = receivedTypes(t, carol)
+	if len(received["chat"]) != 1 || len(received["game_state"]) != 1 {
+		t.Errorf("spectator received %v, want the chat message and the game state after the delay", received)
+	}
+	if received := receivedTypes(t, alice); len(received) != 0 {
+		t.Errorf("player received %v again", received)
+	}
+}
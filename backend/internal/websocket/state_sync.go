@@ -0,0 +1,181 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"game-server/internal/types"
+	"log"
+	"reflect"
+)
+
+// stateSnapshot is a version of a room's game state
+type stateSnapshot struct {
+	version int64
+	// The state encoded, and decoded as generic JSON values to compute patches from
+	data []byte
+	tree interface{}
+	// The game_state message carrying the state
+	message []byte
+}
+
+// handleSyncModeMessage switches the requesting client between full game states
+// and state patches, then sends it a snapshot to apply the patches on
+func handleSyncModeMessage(h *Hub, c *Client, message []byte) {
+	r := c.Room
+
+	var syncModeMessage types.SyncModeMessage
+	if err := json.Unmarshal(message, &syncModeMessage); err != nil {
+		log.Printf("[Error] Invalid sync mode message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	r.mutex.Lock()
+	c.patchSync = syncModeMessage.Patches
+	r.mutex.Unlock()
+	c.sendActionResult(syncModeMessage.MessageID, "sync_mode", nil)
+
+	if err := r.sendSnapshot(c); err != nil {
+		log.Printf("[Error] Failed to send game state: %v", err)
+	}
+}
+
+// handleSyncRequestMessage sends the full game state to a client that missed a patch
+func handleSyncRequestMessage(h *Hub, c *Client, message []byte) {
+	var baseMessage types.BaseMessage
+	if err := json.Unmarshal(message, &baseMessage); err != nil {
+		log.Printf("[Error] Invalid sync request message: %v", err)
+		c.sendError("", types.ReasonInvalidMessage, err.Error())
+		return
+	}
+
+	if err := c.Room.sendSnapshot(c); err != nil {
+		log.Printf("[Error] Failed to send game state: %v", err)
+	}
+}
+
+// snapshot returns the current game state, under a new version if it changed
+// since the last snapshot. The caller must hold the mutex.
+func (r *Room) snapshot() (*stateSnapshot, error) {
+	data, err := json.Marshal(r.currentGameState())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal game state: %w", err)
+	}
+	if r.lastState != nil && bytes.Equal(data, r.lastState.data) {
+		return r.lastState, nil
+	}
+
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to decode game state: %w", err)
+	}
+	message, err := json.Marshal(map[string]interface{}{
+		"type":    "game_state",
+		"version": r.stateVersion + 1,
+		"state":   json.RawMessage(data),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal game state: %w", err)
+	}
+
+	r.stateVersion++
+	r.lastState = &stateSnapshot{
+		version: r.stateVersion,
+		data:    data,
+		tree:    tree,
+		message: message,
+	}
+	return r.lastState, nil
+}
+
+// sendSnapshot sends the full game state to a client, whatever state it was sent
+// before. Spectators of a delayed room get the delayed state.
+func (r *Room) sendSnapshot(client *Client) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	snapshot := r.spectatorState
+	if !r.spectators[client] || r.spectatorDelay == 0 {
+		var err error
+		if snapshot, err = r.snapshot(); err != nil {
+			return err
+		}
+	}
+	if snapshot == nil {
+		return nil
+	}
+
+	client.syncedState = nil
+	if !r.sendState(client, snapshot, nil) {
+		return fmt.Errorf("failed to send game state to client %s", client.ID)
+	}
+	return nil
+}
+
+// sendState sends a game state to a client: as a patch on the last state it was
+// sent if it asked for patches, in full otherwise. Patches are cached by the
+// state they apply on, so that clients in step share them. The caller must hold
+// the mutex.
+func (r *Room) sendState(client *Client, snapshot *stateSnapshot, patches map[*stateSnapshot][]byte) bool {
+	message := snapshot.message
+	if base := client.syncedState; client.patchSync && base != nil {
+		if base == snapshot {
+			return true
+		}
+		patch, cached := patches[base]
+		if !cached {
+			var err error
+			patch, err = json.Marshal(types.StatePatchMessage{
+				Type:        "state_patch",
+				Version:     snapshot.version,
+				BaseVersion: base.version,
+				Patch:       mergePatch(base.tree, snapshot.tree),
+			})
+			if err != nil {
+				log.Printf("[Error] Failed to marshal state patch: %v", err)
+				return true
+			}
+			if patches != nil {
+				patches[base] = patch
+			}
+		}
+		message = patch
+	}
+
+	if !client.trySend(message) {
+		return false
+	}
+	client.syncedState = snapshot
+	return true
+}
+
+// mergePatch returns the JSON merge patch (RFC 7396) turning the generic JSON
+// value from into to. Objects are patched key by key; any other value, arrays
+// included, is replaced as a whole. A merge patch cannot set a member to null:
+// null means delete, so a member that becomes null, such as the position of a
+// character back in the lobby, is removed, and so are the null members of the
+// objects a patch adds. Clients treat a missing member as null.
+func mergePatch(from, to interface{}) interface{} {
+	fromObject, fromIsObject := from.(map[string]interface{})
+	toObject, toIsObject := to.(map[string]interface{})
+	if !fromIsObject || !toIsObject {
+		return to
+	}
+
+	patch := make(map[string]interface{})
+	for key, value := range toObject {
+		previous, existed := fromObject[key]
+		if !existed {
+			patch[key] = value
+		} else if !reflect.DeepEqual(previous, value) {
+			patch[key] = mergePatch(previous, value)
+		}
+	}
+	for key := range fromObject {
+		if _, exists := toObject[key]; !exists {
+			patch[key] = nil
+		}
+	}
+	return patch
+}
@@ -0,0 +1,146 @@
+package websocket
+
+import (
+	"encoding/json"
+	"game-server/internal/game"
+	"game-server/internal/types"
+	"reflect"
+	"testing"
+)
+
+// applyMergePatch applies a JSON merge patch the way clients do, as in RFC 7396
+func applyMergePatch(target, patch interface{}) interface{} {
+	patchObject, isObject := patch.(map[string]interface{})
+	if !isObject {
+		return patch
+	}
+	result := make(map[string]interface{})
+	if targetObject, isObject := target.(map[string]interface{}); isObject {
+		for key, value := range targetObject {
+			result[key] = value
+		}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(result, key)
+		} else {
+			result[key] = applyMergePatch(result[key], value)
+		}
+	}
+	return result
+}
+
+// stripNulls removes the null members of objects, which merge patches cannot
+// carry. Arrays are replaced as a whole by patches, so their values are kept.
+func stripNulls(value interface{}) interface{} {
+	object, isObject := value.(map[string]interface{})
+	if !isObject {
+		return value
+	}
+	stripped := make(map[string]interface{})
+	for key, member := range object {
+		if member != nil {
+			stripped[key] = stripNulls(member)
+		}
+	}
+	return stripped
+}
+
+// stateTree returns the room's current game state as generic JSON values
+func stateTree(t *testing.T, r *Room) interface{} {
+	t.Helper()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	snapshot, err := r.snapshot()
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	return snapshot.tree
+}
+
+// checkMergePatch checks that the patch from base to snapshot, sent over the
+// wire, turns base into snapshot, nulls aside
+func checkMergePatch(t *testing.T, name string, base, snapshot interface{}) {
+	t.Helper()
+	data, err := json.Marshal(mergePatch(base, snapshot))
+	if err != nil {
+		t.Fatalf("%s: failed to marshal patch: %v", name, err)
+	}
+	var patch interface{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		t.Fatalf("%s: failed to decode patch: %v", name, err)
+	}
+	if got, want := stripNulls(applyMergePatch(base, patch)), stripNulls(snapshot); !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: patched state = %v, want %v", name, got, want)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+	}{
+		{"unchanged", `{"a": 1, "b": {"c": 2}}`, `{"a": 1, "b": {"c": 2}}`},
+		{"changed member", `{"a": 1, "b": 2}`, `{"a": 3, "b": 2}`},
+		{"added and removed members", `{"a": 1}`, `{"b": {"c": 2}}`},
+		{"nested object", `{"a": {"b": 1, "c": 2}}`, `{"a": {"b": 1, "c": 3, "d": 4}}`},
+		{"arrays replaced", `{"a": [1, 2, 3]}`, `{"a": [3, null]}`},
+		{"value becoming null", `{"a": {"x": 1}, "b": 2}`, `{"a": null, "b": 2}`},
+		{"null becoming a value", `{"a": null}`, `{"a": {"x": 1}}`},
+		{"added object with nulls", `{}`, `{"a": {"b": null, "c": 1}}`},
+		{"object becoming a scalar", `{"a": {"b": 1}}`, `{"a": "b"}`},
+		{"scalar becoming an object", `{"a": "b"}`, `{"a": {"b": null, "c": 1}}`},
+	}
+	for _, tt := range tests {
+		var from, to interface{}
+		if err := json.Unmarshal([]byte(tt.from), &from); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if err := json.Unmarshal([]byte(tt.to), &to); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		checkMergePatch(t, tt.name, from, to)
+	}
+}
+
+func TestMergePatchFollowsAWholeGame(t *testing.T) {
+	r := newTestRoom(t)
+	clients := []*Client{newTestClient(r, "alice"), newTestClient(r, "bob")}
+	states := []interface{}{stateTree(t, r)}
+
+	startTestFight(t, r, clients, []string{"A", "B"})
+	states = append(states, stateTree(t, r))
+
+	current, _ := r.gameManager.GetCurrentTurnPlayer()
+	position := *r.gameManager.GetCurrentState().Players[current].Character.Position
+	if err := r.moveCharacter(current, types.Position{X: position.X, Y: 1}); err != nil {
+		t.Fatalf("moveCharacter: %v", err)
+	}
+	states = append(states, stateTree(t, r))
+
+	// Back in the lobby, the characters lose their positions
+	if err := r.gameManager.SetGameStatus(game.PhaseFinished); err != nil {
+		t.Fatalf("SetGameStatus: %v", err)
+	}
+	states = append(states, stateTree(t, r))
+	if err := r.gameManager.ResetToLobby(); err != nil {
+		t.Fatalf("ResetToLobby: %v", err)
+	}
+	states = append(states, stateTree(t, r))
+
+	// Clients get patches on the previous state, or on their last full state
+	// after missing some
+	for i := 1; i < len(states); i++ {
+		checkMergePatch(t, "next state", states[i-1], states[i])
+		checkMergePatch(t, "from the lobby", states[0], states[i])
+	}
+
+	// Patches keep applying on a state patched before
+	patched := states[0]
+	for _, state := range states[1:] {
+		patched = applyMergePatch(patched, mergePatch(patched, state))
+	}
+	if got, want := stripNulls(patched), stripNulls(states[len(states)-1]); !reflect.DeepEqual(got, want) {
+		t.Errorf("state patched along the game = %v, want %v", got, want)
+	}
+}
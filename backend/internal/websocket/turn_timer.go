@@ -0,0 +1,137 @@
+package websocket
+
+import (
+	"game-server/internal/game"
+	"log"
+	"time"
+)
+
+// Default turn time limits of a room
+const (
+	defaultTurnDuration = 60 * time.Second
+	defaultTimeBank     = 0
+)
+
+// turnTimer enforces the time limit of the current turn. When the turn time runs
+// out, the player's time bank is used up before the turn ends on its own.
+// It is only used from the hub goroutine.
+type turnTimer struct {
+	turnDuration time.Duration
+	timeBank     time.Duration
+
+	banks     map[string]time.Duration
+	userID    string
+	deadline  time.Time
+	usingBank bool
+	bankStart time.Time
+	timer     *time.Timer
+	// generation tells apart the timeouts of successive turns
+	generation int
+}
+
+func newTurnTimer(turnDuration, timeBank time.Duration) *turnTimer {
+	return &turnTimer{
+		turnDuration: turnDuration,
+		timeBank:     timeBank,
+		banks:        make(map[string]time.Duration),
+	}
+}
+
+// resetTimeBanks gives every player a full time bank at the start of a fight
+func (r *Room) resetTimeBanks() {
+	t := r.turnTimer
+	t.banks = make(map[string]time.Duration)
+	for userID := range r.playerManager.GetPlayers() {
+		t.banks[userID] = t.timeBank
+	}
+}
+
+// startTurnTimer starts counting down the turn of a player
+func (r *Room) startTurnTimer(userID string) {
+	r.stopTurnTimer()
+
+	t := r.turnTimer
+	t.userID = userID
+	t.usingBank = false
+	r.armTurnTimer(t.turnDuration)
+}
+
+// armTurnTimer schedules the timeout of the current turn after d
+func (r *Room) armTurnTimer(d time.Duration) {
+	t := r.turnTimer
+	t.generation++
+	generation := t.generation
+	t.deadline = time.Now().Add(d)
+	t.timer = r.after(d, func() {
+		r.onTurnTimeout(generation)
+	})
+}
+
+// stopTurnTimer stops the countdown of the current turn, charging the time bank
+// for the time it was used
+func (r *Room) stopTurnTimer() {
+	t := r.turnTimer
+	if t.timer == nil {
+		return
+	}
+	t.timer.Stop()
+	t.timer = nil
+	t.generation++
+
+	if t.usingBank {
+		t.banks[t.userID] = max(0, t.banks[t.userID]-time.Since(t.bankStart))
+		t.usingBank = false
+	}
+	t.userID = ""
+}
+
+// onTurnTimeout switches the player to its time bank, or ends its turn once the
+// bank is empty
+func (r *Room) onTurnTimeout(generation int) {
+	t := r.turnTimer
+	if generation != t.generation || r.gameManager.GetStatus() != game.PhaseFighting {
+		return
+	}
+	userID := t.userID
+
+	if !t.usingBank && t.banks[userID] > 0 {
+		log.Printf("[Game] Player %s is using its time bank", userID)
+		t.usingBank = true
+		t.bankStart = time.Now()
+		r.armTurnTimer(t.banks[userID])
+		if err := r.BroadcastGameState(); err != nil {
+			log.Printf("[Error] Failed to broadcast game state: %v", err)
+		}
+		return
+	}
+
+	log.Printf("[Game] Turn of player %s timed out", userID)
+	if err := r.endTurn(userID); err != nil {
+		log.Printf("[Error] Failed to end the timed out turn of player %s: %v", userID, err)
+	}
+}
+
+// turnTimeLeft returns the time left in the current turn, and whether a turn is being timed
+func (r *Room) turnTimeLeft() (time.Duration, bool) {
+	t := r.turnTimer
+	if t.timer == nil {
+		return 0, false
+	}
+	return max(0, time.Until(t.deadline)), true
+}
+
+// timeBanksMs returns the time bank left to each player, in milliseconds
+func (r *Room) timeBanksMs() map[string]int64 {
+	t := r.turnTimer
+	if t.timeBank <= 0 {
+		return nil
+	}
+	banks := make(map[string]int64, len(t.banks))
+	for userID, bank := range t.banks {
+		if t.usingBank && userID == t.userID {
+			bank = max(0, bank-time.Since(t.bankStart))
+		}
+		banks[userID] = bank.Milliseconds()
+	}
+	return banks
+}
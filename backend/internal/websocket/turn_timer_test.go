@@ -0,0 +1,133 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+// runNextTask runs the next scheduled task, as the hub would
+func runNextTask(t *testing.T, tasks <-chan func()) {
+	t.Helper()
+	select {
+	case task := <-tasks:
+		task()
+	case <-time.After(time.Second):
+		t.Fatal("no task was scheduled")
+	}
+}
+
+// startTimedFight starts a fight between alice and bob with the given turn time
+// limits. It returns the room, the channel of its scheduled tasks and the player
+// whose turn it is.
+func startTimedFight(t *testing.T, turnDuration, timeBank time.Duration) (*Room, chan func(), string) {
+	t.Helper()
+	tasks := make(chan func(), 16)
+	r := newTestRoom(t)
+	r.tasks = tasks
+	r.turnTimer = newTurnTimer(turnDuration, timeBank)
+	startTestFight(t, r, []*Client{newTestClient(r, "alice"), newTestClient(r, "bob")}, []string{"A", "B"})
+	current, ok := r.gameManager.GetCurrentTurnPlayer()
+	if !ok {
+		t.Fatal("no one's turn after the fight started")
+	}
+	return r, tasks, current
+}
+
+func TestTurnEndsWhenItsTimeRunsOut(t *testing.T) {
+	r, tasks, first := startTimedFight(t, 10*time.Millisecond, 0)
+
+	runNextTask(t, tasks)
+
+	if next, _ := r.gameManager.GetCurrentTurnPlayer(); next == first {
+		t.Errorf("the turn of %s did not end when its time ran out", first)
+	}
+	if _, timed := r.turnTimeLeft(); !timed {
+		t.Error("the next turn is not timed")
+	}
+}
+
+func TestStaleTurnTimeoutIsIgnored(t *testing.T) {
+	r, tasks, first := startTimedFight(t, 10*time.Millisecond, 0)
+	r.turnTimer.turnDuration = time.Hour
+
+	// The turn times out while the player ends it: the timeout is already queued
+	time.Sleep(20 * time.Millisecond)
+	if err := r.endTurn(first); err != nil {
+		t.Fatalf("endTurn: %v", err)
+	}
+	second, _ := r.gameManager.GetCurrentTurnPlayer()
+
+	runNextTask(t, tasks)
+	if current, _ := r.gameManager.GetCurrentTurnPlayer(); current != second {
+		t.Errorf("turn = %s, want %s: the timeout of the previous turn ended it", current, second)
+	}
+}
+
+func TestTimeBankIsChargedForTheTimeUsed(t *testing.T) {
+	bank := 500 * time.Millisecond
+	r, tasks, first := startTimedFight(t, 10*time.Millisecond, bank)
+
+	// The turn time runs out and the player starts using its bank
+	runNextTask(t, tasks)
+	if current, _ := r.gameManager.GetCurrentTurnPlayer(); current != first {
+		t.Fatalf("turn = %s, want %s still playing on its time bank", current, first)
+	}
+	if !r.turnTimer.usingBank || !r.currentGameState().UsingTimeBank {
+		t.Fatal("the player is not using its time bank")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if err := r.endTurn(first); err != nil {
+		t.Fatalf("endTurn: %v", err)
+	}
+
+	banks := r.timeBanksMs()
+	if left := time.Duration(banks[first]) * time.Millisecond; left >= bank-50*time.Millisecond || left <= 0 {
+		t.Errorf("%s has %v of its bank left, want less than %v", first, left, bank-50*time.Millisecond)
+	}
+	for userID, left := range banks {
+		if userID != first && time.Duration(left)*time.Millisecond != bank {
+			t.Errorf("%s has %dms of its bank left, want all of it", userID, left)
+		}
+	}
+	if r.turnTimer.usingBank {
+		t.Error("the next player starts its turn on its time bank")
+	}
+}
+
+func TestTurnEndsOnceTheTimeBankIsEmpty(t *testing.T) {
+	r, tasks, first := startTimedFight(t, 10*time.Millisecond, 10*time.Millisecond)
+
+	runNextTask(t, tasks)
+	runNextTask(t, tasks)
+
+	if next, _ := r.gameManager.GetCurrentTurnPlayer(); next == first {
+		t.Errorf("the turn of %s did not end with its time bank empty", first)
+	}
+	if left := r.timeBanksMs()[first]; left != 0 {
+		t.Errorf("%s has %dms of its bank left, want none", first, left)
+	}
+}
+
+func TestTimedStateOnlyChangesWithTheGame(t *testing.T) {
+	r, _, _ := startTimedFight(t, time.Hour, 0)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	first, err := r.snapshot()
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	second, err := r.snapshot()
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	// The countdown runs on the clients, from the turn's deadline
+	if second != first {
+		t.Errorf("state version went from %d to %d while the clock ran", first.version, second.version)
+	}
+	if r.currentGameState().TurnEndsAt == 0 {
+		t.Error("the timed turn has no deadline")
+	}
+}
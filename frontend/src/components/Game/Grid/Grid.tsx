@@ -13,6 +13,7 @@ import { useCharacterAnimations } from "../../../hooks/useCharacterAnimations";
 import { useGridInteraction } from "../../../hooks/useGridInteraction";
 import { useTileSize } from "../../../hooks/useTileSize";
 import { GameStateMessage } from "../../../types/message";
+import { useWebSocket } from "../../../context/WebSocketContext";
 
 interface GridProps {
   gridSize: number;
@@ -32,6 +33,8 @@ export const Grid: React.FC<GridProps> = ({
   selectedSpellId,
 }) => {
   const containerRef = useRef<HTMLDivElement>(null);
+  const { spells } = useWebSocket();
+  const selectedSpell = spells.find((spell) => spell.id === selectedSpellId);
 
   const players = latestGameState?.players;
   const currentPlayer = players?.[userId];
@@ -88,7 +91,7 @@ export const Grid: React.FC<GridProps> = ({
     characterPosition,
     movementPoints,
     isCurrentTurn: currentPlayer?.isCurrentTurn || false,
-    selectedSpellId,
+    selectedSpell,
     players,
     initialPositions,
   });
@@ -140,8 +143,8 @@ export const Grid: React.FC<GridProps> = ({
 
         const isInSpellRange = !!(
           characterPosition &&
-          selectedSpellId &&
-          isInSpellAffectedArea({ x, y }, characterPosition, selectedSpellId)
+          selectedSpell &&
+          isInSpellAffectedArea({ x, y }, characterPosition, selectedSpell)
         );
 
         const isImpactedCell = impactedCells.some(
@@ -161,13 +164,9 @@ export const Grid: React.FC<GridProps> = ({
               !findPlayerOnCell(x, y)
             ) ||
             !!(
-              selectedSpellId &&
+              selectedSpell &&
               characterPosition &&
-              isInSpellAffectedArea(
-                { x, y },
-                characterPosition,
-                selectedSpellId
-              )
+              isInSpellAffectedArea({ x, y }, characterPosition, selectedSpell)
             );
 
         const screenPosition = isoToScreen(x, y, tileSize, centerX, centerY);
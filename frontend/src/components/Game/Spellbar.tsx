@@ -1,6 +1,6 @@
 import React from "react";
 import { Heart, Star, Diamond, LucideIcon } from "lucide-react";
-import { SPELLS } from "../../../data/spells";
+import { useWebSocket } from "../../context/WebSocketContext";
 import { PlayerMessage } from "../../types/message";
 
 interface SpellBarProps {
@@ -14,6 +14,8 @@ const SpellBar: React.FC<SpellBarProps> = ({
   selectedSpellId,
   currentPlayer,
 }) => {
+  const { spells: catalogue } = useWebSocket();
+
   const Tooltip: React.FC<{ text: string }> = ({ text }) => (
     <div className="absolute z-50 bottom-full mb-2 left-1/2 -translate-x-1/2 w-48 bg-white text-gray-800 text-xs p-2 rounded-md border border-gray-300 shadow-xl whitespace-pre-line">
       {text}
@@ -21,7 +23,7 @@ const SpellBar: React.FC<SpellBarProps> = ({
   );
 
   const SpellRow = (start: number, end: number) => {
-    const spellsSlice = SPELLS.slice(start, end);
+    const spellsSlice = catalogue.slice(start, end);
     const spells = [
       ...spellsSlice,
       ...Array(10 - spellsSlice.length).fill(null),
@@ -1,6 +1,6 @@
 import { createContext, useContext } from "react";
 import { ChatMessage, GameStateMessage } from "../types/message";
-import { GameAction } from "../types/game";
+import { GameAction, Spell } from "../types/game";
 
 interface WebSocketContextType {
   chatMessages: ChatMessage[];
@@ -12,6 +12,8 @@ interface WebSocketContextType {
   userName: string;
   gameRecord: GameStateMessage[];
   winner: string | null;
+  // Spell catalogue of the server, empty until it is received
+  spells: Spell[];
 }
 
 export const WebSocketContext = createContext<WebSocketContextType | null>(
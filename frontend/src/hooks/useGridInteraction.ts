@@ -1,5 +1,5 @@
 import { useState, useEffect } from "react";
-import { Position, Player } from "../../../types/game";
+import { Position, Player, Spell } from "../../../types/game";
 import { screenToIso, generateIsometricCoordinates } from "../utils/isoUtils";
 import { calculatePath, isWithinRange } from "../utils/pathUtils";
 import { calculateImpactedCells } from "../utils/spellUtils";
@@ -12,7 +12,7 @@ interface UseGridInteractionProps {
   characterPosition: Position | undefined;
   movementPoints: number | undefined;
   isCurrentTurn: boolean;
-  selectedSpellId: number | null;
+  selectedSpell: Spell | undefined;
   players: { [id: string]: Player } | undefined;
   initialPositions: Position[];
 }
@@ -25,7 +25,7 @@ export const useGridInteraction = ({
   characterPosition,
   movementPoints,
   isCurrentTurn,
-  selectedSpellId,
+  selectedSpell,
   players,
   initialPositions,
 }: UseGridInteractionProps) => {
@@ -56,9 +56,9 @@ export const useGridInteraction = ({
         setPathCells([]);
       }
 
-      if (selectedSpellId) {
+      if (selectedSpell) {
         const impacted = calculateImpactedCells(
-          selectedSpellId,
+          selectedSpell,
           hoveredPosition,
           characterPosition
         );
@@ -72,7 +72,7 @@ export const useGridInteraction = ({
     characterPosition,
     movementPoints,
     isCurrentTurn,
-    selectedSpellId,
+    selectedSpell,
     isPositioningPhase,
   ]);
 
@@ -138,7 +138,7 @@ export const useGridInteraction = ({
     isPositioningPhase,
     players,
     initialPositions,
-    selectedSpellId,
+    selectedSpell,
   ]);
 
   return { hoveredPosition, pathCells, impactedCells };
@@ -7,8 +7,10 @@ import {
   UserInitMessage,
   GameOverMessage,
   Message,
+  TeamMember,
+  SpellCatalogueMessage,
 } from "../types/message";
-import { GameAction } from "../types/game";
+import { GameAction, Spell } from "../types/game";
 
 type WebSocketProviderProps = {
   children: React.ReactNode;
@@ -28,6 +30,7 @@ export const WebSocketProvider: React.FC<WebSocketProviderProps> = ({
   const [gameRecord, setGameRecord] = useState<GameState[]>([]);
   const [chatMessages, setChatMessages] = useState<ChatMessage[]>([]);
   const [winner, setWinner] = useState<string | null>(null);
+  const [spells, setSpells] = useState<Spell[]>([]);
   const wsRef = useRef<WebSocket | null>(null);
   const reconnectTimeoutRef = useRef<NodeJS.Timeout>();
 
@@ -37,7 +40,13 @@ export const WebSocketProvider: React.FC<WebSocketProviderProps> = ({
   }, []);
 
   const handleChatMessage = useCallback(
-    (data: ChatMessage | UserInitMessage | GameOverMessage) => {
+    (
+      data:
+        | ChatMessage
+        | UserInitMessage
+        | GameOverMessage
+        | SpellCatalogueMessage
+    ) => {
       console.log("[WebSocket] Processing message:", data);
 
       switch (data.type) {
@@ -45,6 +54,7 @@ export const WebSocketProvider: React.FC<WebSocketProviderProps> = ({
           console.log("[WebSocket] Processing init message:", data);
           localStorage.setItem("userId", data.user.id);
           localStorage.setItem("userName", data.user.name);
+          localStorage.setItem("sessionToken", data.sessionToken);
           setUserId(data.user.id);
           setUserName(data.user.name);
           break;
@@ -54,7 +64,14 @@ export const WebSocketProvider: React.FC<WebSocketProviderProps> = ({
           break;
         case "game_over":
           console.log("[WebSocket] Game Over message:", data);
-          setWinner(data.winner);
+          setWinner(
+            data.members.length > 0
+              ? data.members.map((member: TeamMember) => member.userName).join(", ")
+              : "Nobody"
+          );
+          break;
+        case "spells_catalogue":
+          setSpells(data.spells);
           break;
       }
     },
@@ -70,9 +87,15 @@ export const WebSocketProvider: React.FC<WebSocketProviderProps> = ({
     try {
       console.log("[WebSocket] Connecting...");
       const isDev = import.meta.env.DEV;
-      const wsUrl = isDev
+      const baseUrl = isDev
         ? `ws://localhost:8080/ws`
         : `ws://${window.location.hostname}/ws`;
+      // Resume the previous session if there is one
+      const params = new URLSearchParams();
+      const sessionToken = localStorage.getItem("sessionToken");
+      if (sessionToken) params.set("session", sessionToken);
+      const query = params.toString();
+      const wsUrl = query ? `${baseUrl}?${query}` : baseUrl;
 
       const ws = new WebSocket(wsUrl);
       wsRef.current = ws;
@@ -104,7 +127,11 @@ export const WebSocketProvider: React.FC<WebSocketProviderProps> = ({
             handleGameStatesRecord(data as GameStateMessage);
           } else {
             handleChatMessage(
-              data as ChatMessage | UserInitMessage | GameOverMessage
+              data as
+                | ChatMessage
+                | UserInitMessage
+                | GameOverMessage
+                | SpellCatalogueMessage
             );
           }
         } catch (error) {
@@ -178,6 +205,7 @@ export const WebSocketProvider: React.FC<WebSocketProviderProps> = ({
         userName,
         gameRecord,
         winner,
+        spells,
       }}
     >
       {children}
@@ -1,8 +1,55 @@
+export type EffectKind =
+  | "poison"
+  | "movement_points"
+  | "action_points"
+  | "vulnerability";
+
+export interface SpellEffect {
+  kind: EffectKind;
+  value: number;
+  duration: number; // in turns of the target
+  stackPolicy?: "refresh" | "stack" | "ignore";
+}
+
+// Spells are defined by the server, which sends its catalogue in a
+// spells_catalogue message once connected
+export interface Spell {
+  id: number;
+  name: string;
+  bgColor: string;
+  borderColor: string;
+  icon: string;
+  APCost: number;
+  range: number;
+  needsLineOfSight: boolean;
+  maxCastsPerTurn: number;
+  damage: number;
+  areaOfEffect: "none" | "circle" | "cross" | "line";
+  type: "Fire" | "Water" | "Air" | "Earth" | "Neutral";
+  description?: string;
+  criticalChance?: number; // in %
+  criticalDamage?: number;
+  castInLineOnly?: boolean;
+  castOnEmptyCell?: boolean;
+  cooldown?: number; // in turns
+  isWeapon?: boolean;
+  effects?: SpellEffect[];
+}
+
 export type Position = {
   x: number;
   y: number;
 };
 
+// Rows of cells starting at origin: "." floor, "#" obstacle, "o" hole,
+// an upper case letter a team placement cell and " " no cell.
+export interface BoardMap {
+  id: string;
+  name: string;
+  origin: Position;
+  rows: string[];
+}
+
 export type Character = {
   name: string;
   color: string;
@@ -15,15 +62,30 @@ export type Character = {
   hasPlayedThisTurn: boolean;
   health: number;
   isAlive: boolean;
+  initiative?: number;
+  spellCooldowns?: { [spellId: string]: number };
+  spellCastsThisTurn?: { [spellId: string]: number };
+  effects?: StatusEffect[];
 };
+export interface StatusEffect {
+  kind: EffectKind;
+  value: number;
+  remainingTurns: number;
+  stackPolicy?: "refresh" | "stack" | "ignore";
+  sourceUserId: string;
+  spellId: number;
+}
 export interface Player {
   userId: string;
   userName: string;
+  team: string;
   character: Character;
   status: string;
   isCurrentTurn: boolean;
   isReady: boolean;
   hasPositioned: boolean;
+  isBot?: boolean;
+  botDifficulty?: "easy" | "medium" | "hard";
 }
 
 export interface CastSpellAction {
@@ -1,8 +1,16 @@
-import { Player } from "./game";
+import { BoardMap, Player, Position, Spell } from "./game";
 
 export type UserInfo = {
   id: string;
   name: string;
+  guest: boolean;
+};
+
+// Response of POST /auth/register and /auth/login
+export type AuthResponse = {
+  token: string;
+  expiresAt: string;
+  user: UserInfo;
 };
 
 export type BaseMessage = {
@@ -16,6 +24,8 @@ export interface UserInitMessage extends BaseMessage {
   type: "user_init";
   user: UserInfo;
   gameStatus: "create_character";
+  sessionToken: string;
+  resumed: boolean;
 }
 
 export interface ChatMessage extends BaseMessage {
@@ -29,22 +39,217 @@ export interface GameState {
   turnNumber: number;
   status: string;
   spells: { [key: string]: any };
+  timeline?: string[];
+  currentTurnIndex?: number;
+  map?: BoardMap;
+  friendlyFire?: boolean;
+  turnEndsAt?: number; // Unix time in milliseconds
+  usingTimeBank?: boolean;
+  timeBanksMs?: { [userId: string]: number };
 }
 
 export interface GameStateMessage {
   type: "game_state";
   state: GameState;
+  // Version of the room's state, which state patches apply on
+  version?: number;
+  // Set when the state is a frame of a replay being watched
+  replayGameId?: string;
+}
+
+// Changes of the game state as a JSON merge patch, sent instead of game_state
+// once a client asked for patches with a sync_mode message. A client whose state
+// is not of version baseVersion sends a sync_request to get a full game_state.
+// Fields that are null in a full state are missing from a patched one.
+export interface StatePatchMessage {
+  type: "state_patch";
+  version: number;
+  baseVersion: number;
+  patch: Partial<GameState>;
 }
 
 export type MessageType = "chat" | "game_action" | "game_state" | "user_init";
 
+export interface TeamMember {
+  userId: string;
+  userName: string;
+}
+
 export interface GameOverMessage {
   type: "game_over";
-  winner: string;
+  winningTeam: string;
+  members: TeamMember[];
+}
+
+export interface SpellHit {
+  userId: string;
+  position: Position;
+  damage: number;
+  critical: boolean;
+  isDead: boolean;
+}
+
+// Combat messages, broadcast before the game state an action leads to
+export interface SpellCastMessage {
+  type: "spell_cast";
+  casterId: string;
+  spellId: number;
+  targetPosition: Position;
+  affectedCells: Position[];
+  critical: boolean;
+  hits: SpellHit[];
+}
+
+export interface CharacterMovedMessage {
+  type: "character_moved";
+  userId: string;
+  from: Position;
+  path: Position[];
+}
+
+export interface CharacterDiedMessage {
+  type: "character_died";
+  userId: string;
+  killerId?: string;
+  // Spell of the killing hit, missing when a status effect killed the character
+  spellId?: number;
+}
+
+export interface TurnStartedMessage {
+  type: "turn_started";
+  userId: string;
+  turnNumber: number;
+  turnEndsAt?: number;
+}
+
+// Answers of get_reachable_cells and get_spell_targets, computed by the server
+// with the rules it applies to moves and casts
+export interface ReachableCell extends Position {
+  cost: number;
+}
+
+export interface ReachableCellsMessage {
+  type: "reachable_cells";
+  messageId: string;
+  cells: ReachableCell[];
+}
+
+export interface SpellTargetsMessage {
+  type: "spell_targets";
+  messageId: string;
+  spellId: number;
+  targets: Position[];
+  target?: Position;
+  // Set when target is one of the targets
+  affectedCells?: Position[];
+  hits?: SpellHit[];
+}
+
+export interface ReplayFinishedMessage {
+  type: "replay_finished";
+  gameId: string;
+}
+
+export interface Rating {
+  userId: string;
+  userName: string;
+  mode: string;
+  rating: number;
+  gamesPlayed: number;
+  wins: number;
+  losses: number;
+  draws: number;
+  updatedAt: string;
+}
+
+export interface MatchFoundMessage {
+  type: "match_found";
+  mode: string;
+  roomId: string;
+  team: string;
+}
+
+export interface LeaderboardMessage {
+  type: "leaderboard";
+  mode: string;
+  ratings: Rating[];
+}
+
+export interface SpellCatalogueMessage {
+  type: "spells_catalogue";
+  spells: Spell[];
+}
+
+export type ReasonCode =
+  | "NOT_YOUR_TURN"
+  | "NOT_ENOUGH_AP"
+  | "NOT_ENOUGH_MP"
+  | "OUT_OF_RANGE"
+  | "UNKNOWN_SPELL"
+  | "SPELL_ON_COOLDOWN"
+  | "MAX_CASTS_REACHED"
+  | "NO_LINE_OF_SIGHT"
+  | "NOT_IN_LINE"
+  | "INVALID_TARGET"
+  | "OFF_BOARD"
+  | "CELL_OCCUPIED"
+  | "NO_PATH"
+  | "NOT_WALKABLE"
+  | "INVALID_PLACEMENT"
+  | "PLAYER_NOT_FOUND"
+  | "NO_POSITION"
+  | "CHARACTER_DEAD"
+  | "UNKNOWN_ROOM"
+  | "UNKNOWN_MAP"
+  | "UNKNOWN_TEAM"
+  | "UNKNOWN_DIFFICULTY"
+  | "UNKNOWN_REPLAY"
+  | "UNKNOWN_MODE"
+  | "RANKED_ROOM"
+  | "ACCOUNT_REQUIRED"
+  | "IN_FIGHT"
+  | "NOT_ENOUGH_PLAYERS"
+  | "PLAYERS_NOT_READY"
+  | "SINGLE_TEAM"
+  | "NOT_IN_ROOM"
+  | "SPECTATOR"
+  | "WRONG_PHASE"
+  | "INVALID_MESSAGE"
+  | "UNKNOWN_MESSAGE_TYPE"
+  | "IDENTITY_MISMATCH"
+  | "INTERNAL_ERROR";
+
+export interface ActionResultMessage {
+  type: "action_result";
+  messageId: string;
+  action: string;
+  success: boolean;
+  code?: ReasonCode;
+  reason?: string;
+}
+
+export interface ErrorMessage {
+  type: "error";
+  messageId?: string;
+  code: ReasonCode;
+  reason: string;
 }
 
 export type Message =
   | UserInitMessage
   | ChatMessage
   | GameStateMessage
-  | GameOverMessage;
+  | GameOverMessage
+  | ReplayFinishedMessage
+  | StatePatchMessage
+  | SpellCastMessage
+  | CharacterMovedMessage
+  | CharacterDiedMessage
+  | TurnStartedMessage
+  | ReachableCellsMessage
+  | SpellTargetsMessage
+  | MatchFoundMessage
+  | LeaderboardMessage
+  | SpellCatalogueMessage
+  | ActionResultMessage
+  | ErrorMessage;
@@ -1,5 +1,4 @@
-import { Spell, SPELLS } from "../../data/spells";
-import { Position } from "../types/game";
+import { Position, Spell } from "../types/game";
 
 // Rotate a position based on the direction
 const rotate = (
@@ -36,15 +35,10 @@ function getDirection(
 export function isInSpellAffectedArea(
   center: Position,
   casterPos: Position,
-  spellId: number
+  spell: Spell
 ): boolean {
   const dx = center.x - casterPos.x;
   const dy = center.y - casterPos.y;
-  const spell = SPELLS[spellId - 1];
-  if (!spell) {
-    console.error(`Spell with ID ${spellId} not found`);
-    return false;
-  }
   const distance = Math.abs(dx) + Math.abs(dy);
   if (distance > spell.range) {
     return false;
@@ -82,20 +76,14 @@ export function getCellsInRange(spell: Spell, casterPos: Position): Position[] {
   return affectedCells;
 }
 
-// Calculate impacted cells by a spell given its ID and target position.
+// Calculate impacted cells by a spell given its target position.
 export function calculateImpactedCells(
-  spellId: number,
+  spell: Spell,
   targetPos: Position,
   casterPosition: Position
 ): Position[] {
-  const spell = SPELLS[spellId - 1];
   const impactedCells: Position[] = [];
 
-  if (!spell) {
-    console.error(`Spell with ID ${spellId} not found`);
-    return impactedCells;
-  }
-
   const applyPattern = (
     pattern: Position[],
     rotatePattern: boolean = false